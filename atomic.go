@@ -0,0 +1,346 @@
+package cache
+
+import (
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AtomicNumericCache implements NumericCacher. Each item is an atomic cell, so
+// that Get, Increment and Decrement on existing keys only take the cache's read
+// lock and do not contend with each other. Set, Add, Replace and the deletions
+// take the write lock.
+//
+// It trades the extra features of NumericCache (statistics, logging, metadata,
+// negative entries, top keys...) for the throughput of concurrent increments.
+type AtomicNumericCache[T Numeric] struct {
+	*atomicNumericCache[T]
+	// If this is confusing, see the comment at the bottom of New()
+}
+
+type atomicNumericCache[T Numeric] struct {
+	defaultExpiration time.Duration
+	items             map[string]*atomicCell
+	mu                sync.RWMutex
+	onEvicted         func(string, T)
+	janitor           *janitor[T]
+	// float is whether T is a floating-point type, whose cells are updated
+	// with a compare-and-swap loop.
+	float bool
+}
+
+// atomicCell holds the value of an item as bits: the two's complement of
+// integers, which can be added atomically whatever their size as the value is
+// truncated when read, and the IEEE 754 binary representation of floats as
+// float64. The expiration of a cell never changes, setting an item replaces its
+// cell.
+type atomicCell struct {
+	bits       atomic.Uint64
+	expiration int64
+}
+
+func (c *atomicNumericCache[T]) toBits(x T) uint64 {
+	if c.float {
+		return math.Float64bits(float64(x))
+	}
+	return uint64(x)
+}
+
+func (c *atomicNumericCache[T]) fromBits(b uint64) T {
+	if c.float {
+		return T(math.Float64frombits(b))
+	}
+	return T(b)
+}
+
+func (c *atomicNumericCache[T]) newCell(x T, d time.Duration) *atomicCell {
+	var e int64
+
+	if d == DefaultExpiration {
+		d = c.defaultExpiration
+	}
+
+	if d > 0 {
+		e = time.Now().Add(d).UnixNano()
+	}
+
+	cell := &atomicCell{expiration: e}
+	cell.bits.Store(c.toBits(x))
+	return cell
+}
+
+func (cell *atomicCell) expired() bool {
+	return cell.expiration > 0 && time.Now().UnixNano() > cell.expiration
+}
+
+// add adds n to the value of the cell and returns the result.
+func (c *atomicNumericCache[T]) add(cell *atomicCell, n T) T {
+	if !c.float {
+		return c.fromBits(cell.bits.Add(c.toBits(n)))
+	}
+	for {
+		old := cell.bits.Load()
+		nv := c.fromBits(old) + n
+		if cell.bits.CompareAndSwap(old, c.toBits(nv)) {
+			return nv
+		}
+	}
+}
+
+// sub subtracts n from the value of the cell and returns the result.
+func (c *atomicNumericCache[T]) sub(cell *atomicCell, n T) T {
+	if !c.float {
+		return c.fromBits(cell.bits.Add(-c.toBits(n)))
+	}
+	for {
+		old := cell.bits.Load()
+		nv := c.fromBits(old) - n
+		if cell.bits.CompareAndSwap(old, c.toBits(nv)) {
+			return nv
+		}
+	}
+}
+
+// Set adds an item to the cache, replacing any existing item. If the duration is 0
+// (DefaultExpiration), the cache's default expiration time is used. If it is -1
+// (NoExpiration), the item never expires.
+func (c *atomicNumericCache[T]) Set(k string, x T, d time.Duration) {
+	cell := c.newCell(x, d)
+
+	c.mu.Lock()
+	c.items[k] = cell
+	c.mu.Unlock()
+}
+
+// SetDefault adds an item to the cache, replacing any existing item, using the default
+// expiration.
+func (c *atomicNumericCache[T]) SetDefault(k string, x T) {
+	c.Set(k, x, DefaultExpiration)
+}
+
+// Add an item to the cache only if an item doesn't already exist for the given
+// key, or if the existing item has expired. Returns ErrAlreadyExists otherwise.
+func (c *atomicNumericCache[T]) Add(k string, x T, d time.Duration) error {
+	cell := c.newCell(x, d)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, found := c.items[k]; found && !old.expired() {
+		return keyError(k, ErrAlreadyExists)
+	}
+	c.items[k] = cell
+	return nil
+}
+
+// Replace replaces a new value for the cache key only if it already exists, and the existing
+// item hasn't expired. Returns ErrNotFound otherwise.
+func (c *atomicNumericCache[T]) Replace(k string, x T, d time.Duration) error {
+	cell := c.newCell(x, d)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if old, found := c.items[k]; !found || old.expired() {
+		return keyError(k, ErrNotFound)
+	}
+	c.items[k] = cell
+	return nil
+}
+
+// Get gets an item from the cache. Returns the item or nil, and a bool indicating
+// whether the key was found.
+func (c *atomicNumericCache[T]) Get(k string) (T, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cell, found := c.items[k]
+	if !found || cell.expired() {
+		var ret T
+		return ret, false
+	}
+	return c.fromBits(cell.bits.Load()), true
+}
+
+// GetWithExpiration returns an item and its expiration time from the cache.
+// It returns the item or nil, the expiration time if one is set (if the item
+// never expires a zero value for time.Time is returned), and a bool indicating
+// whether the key was found.
+func (c *atomicNumericCache[T]) GetWithExpiration(k string) (T, time.Time, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cell, found := c.items[k]
+	if !found || cell.expired() {
+		var ret T
+		return ret, time.Time{}, false
+	}
+	if cell.expiration > 0 {
+		return c.fromBits(cell.bits.Load()), time.Unix(0, cell.expiration), true
+	}
+	return c.fromBits(cell.bits.Load()), time.Time{}, true
+}
+
+// Increment increments an item by n, wrapping around on overflow like
+// NumericCache.Increment. Returns ErrNotFound if the item was not found or has
+// expired. Only the read lock of the cache is taken.
+func (c *atomicNumericCache[T]) Increment(k string, n T) (T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cell, found := c.items[k]
+	if !found || cell.expired() {
+		var ret T
+		return ret, keyError(k, ErrNotFound)
+	}
+	return c.add(cell, n), nil
+}
+
+// Decrement decrements an item by n, wrapping around on overflow like
+// NumericCache.Decrement. Returns ErrNotFound if the item was not found or has
+// expired. Only the read lock of the cache is taken.
+func (c *atomicNumericCache[T]) Decrement(k string, n T) (T, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cell, found := c.items[k]
+	if !found || cell.expired() {
+		var ret T
+		return ret, keyError(k, ErrNotFound)
+	}
+	return c.sub(cell, n), nil
+}
+
+// Delete deletes an item from the cache. Does nothing if the key is not in the cache.
+func (c *atomicNumericCache[T]) Delete(k string) {
+	c.mu.Lock()
+	cell, found := c.items[k]
+	if found {
+		delete(c.items, k)
+	}
+	onEvicted := c.onEvicted
+	c.mu.Unlock()
+
+	if found && onEvicted != nil {
+		onEvicted(k, c.fromBits(cell.bits.Load()))
+	}
+}
+
+// DeleteExpired deletes all expired items from the cache.
+func (c *atomicNumericCache[T]) DeleteExpired() {
+	var evictedItems []keyAndValue[T]
+	now := time.Now().UnixNano()
+	c.mu.Lock()
+	onEvicted := c.onEvicted
+	for k, cell := range c.items {
+		if cell.expiration > 0 && now > cell.expiration {
+			delete(c.items, k)
+			if onEvicted != nil {
+				evictedItems = append(evictedItems, keyAndValue[T]{k, c.fromBits(cell.bits.Load())})
+			}
+		}
+	}
+	c.mu.Unlock()
+	for _, v := range evictedItems {
+		onEvicted(v.key, v.value)
+	}
+}
+
+func (c *atomicNumericCache[T]) stopJanitor() {
+	c.janitor.stop <- true
+}
+
+func (c *atomicNumericCache[T]) setJanitor(j *janitor[T]) {
+	c.janitor = j
+}
+
+// OnEvicted sets an (optional) function that is called with the key and value when an
+// item is evicted from the cache. (Including when it is deleted manually, but
+// not when it is overwritten.) Set to nil to disable.
+func (c *atomicNumericCache[T]) OnEvicted(f func(string, T)) {
+	c.mu.Lock()
+	c.onEvicted = f
+	c.mu.Unlock()
+}
+
+// Items copies all unexpired items in the cache into a new map and returns it.
+func (c *atomicNumericCache[T]) Items() map[string]Item[T] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := make(map[string]Item[T], len(c.items))
+	now := time.Now().UnixNano()
+	for k, cell := range c.items {
+		if cell.expiration > 0 && now > cell.expiration {
+			continue
+		}
+		m[k] = Item[T]{
+			Object:     c.fromBits(cell.bits.Load()),
+			Expiration: cell.expiration,
+		}
+	}
+	return m
+}
+
+// ItemCount returns the number of items in the cache. This may include items that have
+// expired, but have not yet been cleaned up.
+func (c *atomicNumericCache[T]) ItemCount() int {
+	c.mu.RLock()
+	n := len(c.items)
+	c.mu.RUnlock()
+	return n
+}
+
+// Flush deletes all items from the cache.
+func (c *atomicNumericCache[T]) Flush() {
+	c.mu.Lock()
+	c.items = map[string]*atomicCell{}
+	c.mu.Unlock()
+}
+
+func newAtomicNumericCache[T Numeric](de time.Duration, m map[string]Item[T]) *atomicNumericCache[T] {
+	if de == 0 {
+		de = -1
+	}
+	c := &atomicNumericCache[T]{
+		defaultExpiration: de,
+		items:             make(map[string]*atomicCell, len(m)),
+		float:             isFloat[T](),
+	}
+	for k, v := range m {
+		cell := &atomicCell{expiration: v.Expiration}
+		cell.bits.Store(c.toBits(v.Object))
+		c.items[k] = cell
+	}
+	return c
+}
+
+func newAtomicNumericCacheWithJanitor[T Numeric](de time.Duration, ci time.Duration, m map[string]Item[T]) *AtomicNumericCache[T] {
+	c := newAtomicNumericCache(de, m)
+	// See newAnyCacheWithJanitor.
+	C := &AtomicNumericCache[T]{c}
+
+	if ci > 0 {
+		runJanitor[T](c, ci)
+		runtime.SetFinalizer(C, stopJanitor[T])
+	}
+	return C
+}
+
+// NewAtomicNumeric[T Numeric](...) returns a new *AtomicNumericCache[T] with a
+// given default expiration duration and cleanup interval, see NewAny.
+func NewAtomicNumeric[T Numeric](defaultExpiration, cleanupInterval time.Duration) *AtomicNumericCache[T] {
+	return newAtomicNumericCacheWithJanitor[T](defaultExpiration, cleanupInterval, nil)
+}
+
+// NewAtomicNumericCacher[T Numeric](...) returns a NumericCacher[T] interface.
+func NewAtomicNumericCacher[T Numeric](defaultExpiration, cleanupInterval time.Duration) NumericCacher[T] {
+	return NewAtomicNumeric[T](defaultExpiration, cleanupInterval)
+}
+
+// NewAtomicNumericFrom[T Numeric](...) returns a new *AtomicNumericCache[T]
+// holding a copy of the given items, see NewAnyFrom.
+func NewAtomicNumericFrom[T Numeric](defaultExpiration, cleanupInterval time.Duration, items map[string]Item[T]) *AtomicNumericCache[T] {
+	return newAtomicNumericCacheWithJanitor(defaultExpiration, cleanupInterval, items)
+}
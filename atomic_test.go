@@ -0,0 +1,234 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+var _ NumericCacher[int] = NewAtomicNumeric[int](DefaultExpiration, 0)
+
+func testAtomicIncrement[T Numeric](t *testing.T, name string) {
+	t.Helper()
+	min, max := numericBounds[T]()
+	tc := NewAtomicNumeric[T](DefaultExpiration, 0)
+
+	tc.Set("foo", 1, DefaultExpiration)
+	if x, err := tc.Increment("foo", 2); err != nil || x != 3 {
+		t.Errorf("%s: Increment failed: %v, %v", name, x, err)
+	}
+	if x, err := tc.Decrement("foo", 1); err != nil || x != 2 {
+		t.Errorf("%s: Decrement failed: %v, %v", name, x, err)
+	}
+	if x, _ := tc.Get("foo"); x != 2 {
+		t.Errorf("%s: unexpected value: %v", name, x)
+	}
+
+	tc.Set("max", max, DefaultExpiration)
+	tc.Set("min", min, DefaultExpiration)
+	if isFloat[T]() {
+		return
+	}
+	// Integers wrap around like with NumericCache.
+	if x, _ := tc.Increment("max", 1); x != min {
+		t.Errorf("%s: Increment did not wrap around: %v", name, x)
+	}
+	if x, _ := tc.Decrement("max", 1); x != max {
+		t.Errorf("%s: Decrement did not wrap around: %v", name, x)
+	}
+	if x, _ := tc.Decrement("min", 1); x != max {
+		t.Errorf("%s: Decrement did not wrap around: %v", name, x)
+	}
+}
+
+func TestAtomicNumericIncrement(t *testing.T) {
+	testAtomicIncrement[int8](t, "int8")
+	testAtomicIncrement[int16](t, "int16")
+	testAtomicIncrement[int32](t, "int32")
+	testAtomicIncrement[int64](t, "int64")
+	testAtomicIncrement[int](t, "int")
+	testAtomicIncrement[quota](t, "quota")
+	testAtomicIncrement[uint8](t, "uint8")
+	testAtomicIncrement[uint16](t, "uint16")
+	testAtomicIncrement[uint32](t, "uint32")
+	testAtomicIncrement[uint64](t, "uint64")
+	testAtomicIncrement[uint](t, "uint")
+	testAtomicIncrement[uintptr](t, "uintptr")
+	testAtomicIncrement[float32](t, "float32")
+	testAtomicIncrement[float64](t, "float64")
+}
+
+func TestAtomicNumericNegative(t *testing.T) {
+	tc := NewAtomicNumeric[int32](DefaultExpiration, 0)
+	tc.Set("foo", -5, DefaultExpiration)
+	if x, _ := tc.Increment("foo", -5); x != -10 {
+		t.Error("unexpected value:", x)
+	}
+
+	tf := NewAtomicNumeric[float64](DefaultExpiration, 0)
+	tf.Set("foo", 0.5, DefaultExpiration)
+	if x, _ := tf.Decrement("foo", 1.25); x != -0.75 {
+		t.Error("unexpected value:", x)
+	}
+	tf.Set("inf", math.Inf(1), DefaultExpiration)
+	if x, _ := tf.Increment("inf", 1); !math.IsInf(x, 1) {
+		t.Error("unexpected value:", x)
+	}
+}
+
+func TestAtomicNumericCache(t *testing.T) {
+	tc := NewAtomicNumeric[int](DefaultExpiration, 0)
+
+	if _, err := tc.Increment("foo", 1); !errors.Is(err, ErrNotFound) {
+		t.Error("Increment did not return ErrNotFound:", err)
+	}
+	if err := tc.Replace("foo", 1, DefaultExpiration); !errors.Is(err, ErrNotFound) {
+		t.Error("Replace did not return ErrNotFound:", err)
+	}
+	if err := tc.Add("foo", 1, DefaultExpiration); err != nil {
+		t.Error("Add failed:", err)
+	}
+	if err := tc.Add("foo", 2, DefaultExpiration); !errors.Is(err, ErrAlreadyExists) {
+		t.Error("Add did not return ErrAlreadyExists:", err)
+	}
+	if err := tc.Replace("foo", 3, 50*time.Millisecond); err != nil {
+		t.Error("Replace failed:", err)
+	}
+	if x, e, found := tc.GetWithExpiration("foo"); !found || x != 3 || e.IsZero() {
+		t.Error("GetWithExpiration failed:", x, e, found)
+	}
+
+	tc.Set("expired", 1, time.Nanosecond)
+	<-time.After(time.Millisecond)
+	if _, found := tc.Get("expired"); found {
+		t.Error("found an expired item")
+	}
+	if _, err := tc.Increment("expired", 1); !errors.Is(err, ErrNotFound) {
+		t.Error("incremented an expired item:", err)
+	}
+	if n := len(tc.Items()); n != 1 {
+		t.Error("unexpected number of items:", n)
+	}
+
+	var evicted []string
+	tc.OnEvicted(func(k string, v int) {
+		evicted = append(evicted, k+"="+strconv.Itoa(v))
+	})
+	tc.DeleteExpired()
+	if tc.ItemCount() != 1 || len(evicted) != 1 || evicted[0] != "expired=1" {
+		t.Error("DeleteExpired failed:", tc.ItemCount(), evicted)
+	}
+	tc.Delete("foo")
+	if tc.ItemCount() != 0 || len(evicted) != 2 || evicted[1] != "foo=3" {
+		t.Error("Delete failed:", tc.ItemCount(), evicted)
+	}
+
+	tc.Set("bar", 1, DefaultExpiration)
+	tc.Flush()
+	if tc.ItemCount() != 0 {
+		t.Error("Flush did not delete all items")
+	}
+
+	tf := NewAtomicNumericFrom[int](DefaultExpiration, 0, map[string]Item[int]{
+		"foo": {Object: 42},
+	})
+	if x, _ := tf.Get("foo"); x != 42 {
+		t.Error("NewAtomicNumericFrom did not copy the items:", x)
+	}
+}
+
+func TestAtomicNumericConcurrent(t *testing.T) {
+	ti := NewAtomicNumeric[uint32](DefaultExpiration, 0)
+	ti.Set("foo", 0, DefaultExpiration)
+	tf := NewAtomicNumeric[float32](DefaultExpiration, 0)
+	tf.Set("foo", 0, DefaultExpiration)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ti.Increment("foo", 1)
+				tf.Increment("foo", 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if x, _ := ti.Get("foo"); x != 5000 {
+		t.Error("concurrent increments were lost:", x)
+	}
+	if x, _ := tf.Get("foo"); x != 5000 {
+		t.Error("concurrent float increments were lost:", x)
+	}
+}
+
+func TestAtomicNumericJanitor(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	defer runtime.GC() // Force gc before verifying there are no leaked goroutines
+
+	func() {
+		tc := NewAtomicNumeric[int](time.Millisecond, time.Millisecond)
+		tc.Set("foo", 1, DefaultExpiration)
+		<-time.After(20 * time.Millisecond)
+		if n := tc.ItemCount(); n != 0 {
+			t.Error("janitor did not delete the expired item:", n)
+		}
+	}()
+}
+
+type incrementer interface {
+	Set(k string, x int64, d time.Duration)
+	Increment(k string, n int64) (int64, error)
+}
+
+func benchmarkIncrementParallel(b *testing.B, tc incrementer, keys int) {
+	ks := make([]string, keys)
+	for i := range ks {
+		ks[i] = "foo" + strconv.Itoa(i)
+		tc.Set(ks[i], 0, DefaultExpiration)
+	}
+	var worker uint32
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		k := ks[int(atomic.AddUint32(&worker, 1))%len(ks)]
+		for pb.Next() {
+			tc.Increment(k, 1)
+		}
+	})
+}
+
+func BenchmarkNumericIncrementParallelSameKey(b *testing.B) {
+	benchmarkIncrementParallel(b, NewNumeric[int64](DefaultExpiration, 0), 1)
+}
+
+func BenchmarkNumericIncrementParallelDifferentKeys(b *testing.B) {
+	benchmarkIncrementParallel(b, NewNumeric[int64](DefaultExpiration, 0), 64)
+}
+
+func BenchmarkAtomicNumericIncrementParallelSameKey(b *testing.B) {
+	benchmarkIncrementParallel(b, NewAtomicNumeric[int64](DefaultExpiration, 0), 1)
+}
+
+func BenchmarkAtomicNumericIncrementParallelDifferentKeys(b *testing.B) {
+	benchmarkIncrementParallel(b, NewAtomicNumeric[int64](DefaultExpiration, 0), 64)
+}
+
+func BenchmarkAtomicNumericIncrementFloatParallelSameKey(b *testing.B) {
+	tc := NewAtomicNumeric[float64](DefaultExpiration, 0)
+	tc.Set("foo", 0, DefaultExpiration)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tc.Increment("foo", 1)
+		}
+	})
+}
@@ -1,9 +1,9 @@
 package cache
 
 import (
-	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,6 +11,9 @@ import (
 type Item[T any] struct {
 	Object     T
 	Expiration int64
+	// Version is changed every time the item is set or modified, see
+	// GetWithVersion and SetIfVersion.
+	Version uint64
 }
 
 // Expired returns true if the item has expired.
@@ -41,48 +44,120 @@ type anyCache[T any] struct {
 	items             map[string]Item[T]
 	mu                sync.RWMutex
 	onEvicted         func(string, T)
-	janitor           *janitor[T]
+	// onEvictedWithReason is called along with onEvicted, with the reason of
+	// the eviction.
+	onEvictedWithReason func(string, T, EvictionReason)
+	janitor             *janitor[T]
+	loader              batchLoader[T]
+	// missing holds the expiration of negative entries. It is only allocated
+	// once SetMissing has been called.
+	missing           map[string]int64
+	missingExpiration time.Duration
+	stats             atomic.Pointer[cacheStats]
+	logger            atomic.Pointer[cacheLogger]
+	logRateLimit      int
+	// meta holds the metadata of items. It is only allocated once
+	// EnableMetadata has been called.
+	meta map[string]*itemMeta
+	topK atomic.Pointer[topKTracker]
+	// version is the last version given to an item.
+	version uint64
+	// index is the radix tree of the keys. It is only allocated once
+	// EnableKeyIndex has been called.
+	index *keyIndex
+	// tags is the index of the tags of items. It is only allocated once
+	// SetWithTags has been called with tags.
+	tags *tagIndex
+	// deps is the index of the dependencies of items. It is only allocated
+	// once SetWithDependencies has been called with dependencies.
+	deps *dependencyIndex
+	// cascaded holds the items deleted because one of their dependencies
+	// changed, until the cache is unlocked.
+	cascaded []keyAndValue[T]
 }
 
 // Set adds an item to the cache, replacing any existing item. If the duration is 0
 // (DefaultExpiration), the cache's default expiration time is used. If it is -1
 // (NoExpiration), the item never expires.
 func (c *anyCache[T]) Set(k string, x T, d time.Duration) {
-	// "Inlining" of set
-	var e int64
+	// The expiration and the statistics are computed before locking the cache
+	// to keep the write lock as short as possible.
+	e := c.expiration(d)
+	c.stats.Load().addSets(1)
+	c.observeKey(k)
 
-	if d == DefaultExpiration {
-		d = c.defaultExpiration
-	}
+	c.mu.Lock()
+	defer c.unlock()
 
-	if d > 0 {
-		e = time.Now().Add(d).UnixNano()
-	}
+	c.setItem(k, x, e)
+}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+func (c *anyCache[T]) set(k string, x T, d time.Duration) {
+	c.stats.Load().addSets(1)
+	c.observeKey(k)
+	c.setItem(k, x, c.expiration(d))
+}
 
+// setItem stores an item with the given expiration time, replacing any
+// existing item, and updates the negative entries, metadata and indexes of the
+// key. The cache must be locked.
+func (c *anyCache[T]) setItem(k string, x T, e int64) {
 	c.items[k] = Item[T]{
 		Object:     x,
 		Expiration: e,
+		Version:    c.nextVersion(),
+	}
+	if c.missing != nil {
+		delete(c.missing, k)
+	}
+	if c.meta != nil {
+		c.touch(k, time.Now().UnixNano())
+	}
+	if c.index != nil {
+		c.index.insert(k)
+	}
+	if c.tags != nil {
+		c.tags.untag(k)
+	}
+	if c.deps != nil {
+		c.deps.remove(k)
+		c.cascade(k)
 	}
 }
 
-func (c *anyCache[T]) set(k string, x T, d time.Duration) {
-	var e int64
-
+// expiration returns the expiration time of an item set now with the given
+// duration, handled as in Set.
+func (c *anyCache[T]) expiration(d time.Duration) int64 {
 	if d == DefaultExpiration {
 		d = c.defaultExpiration
 	}
-
 	if d > 0 {
-		e = time.Now().Add(d).UnixNano()
+		return time.Now().Add(d).UnixNano()
 	}
+	return 0
+}
 
-	c.items[k] = Item[T]{
-		Object:     x,
-		Expiration: e,
+// SetMany adds all the given items to the cache under a single lock, replacing
+// any existing ones, and returns the number of items set. The duration is
+// handled as in Set.
+func (c *anyCache[T]) SetMany(items map[string]T, d time.Duration) int {
+	e := c.expiration(d)
+
+	c.stats.Load().addSets(uint64(len(items)))
+	if c.topK.Load() != nil {
+		for k := range items {
+			c.observeKey(k)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	for k, x := range items {
+		c.setItem(k, x, e)
 	}
+
+	return len(items)
 }
 
 // SetDefault adds an item to the cache, replacing any existing item, using the default
@@ -92,83 +167,289 @@ func (c *anyCache[T]) SetDefault(k string, x T) {
 }
 
 // Add an item to the cache only if an item doesn't already exist for the given
-// key, or if the existing item has expired. Returns an error otherwise.
+// key, or if the existing item has expired. Returns ErrAlreadyExists otherwise.
 func (c *anyCache[T]) Add(k string, x T, d time.Duration) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 
 	_, found := c.get(k)
 	if found {
-		return fmt.Errorf("Item %s already exists", k)
+		return keyError(k, ErrAlreadyExists)
 	}
 	c.set(k, x, d)
 	return nil
 }
 
 // Replace replaces a new value for the cache key only if it already exists, and the existing
-// item hasn't expired. Returns an error otherwise.
+// item hasn't expired. Returns ErrNotFound otherwise.
 func (c *anyCache[T]) Replace(k string, x T, d time.Duration) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 
 	_, found := c.get(k)
 	if !found {
-		return fmt.Errorf("Item %s doesn't exist", k)
+		return keyError(k, ErrNotFound)
 	}
 	c.set(k, x, d)
 	return nil
 }
 
+// Update atomically reads and modifies the item for the given key. f is called
+// with the current value, or the zero value of T, and whether the key exists
+// and hasn't expired. If f returns keep as true its new value is stored, keeping
+// the current expiration if the key exists or using the default expiration
+// otherwise. If keep is false the item is deleted. Update returns the value
+// returned by f and keep. f is called with the cache locked and must not call
+// the cache's methods.
+func (c *anyCache[T]) Update(k string, f func(old T, exists bool) (new T, keep bool)) (T, bool) {
+	c.mu.Lock()
+	old, exists := c.get(k)
+	if !exists {
+		var zero T
+		old = zero
+	}
+	x, keep := f(old, exists)
+	if !keep {
+		v, evicted := c.delete(k)
+		c.unlock()
+		if evicted {
+			c.evicted(k, v, deletionReason(exists))
+		}
+		return x, false
+	}
+	if exists {
+		c.replaceObject(k, x)
+	} else {
+		c.set(k, x, DefaultExpiration)
+	}
+	c.unlock()
+	return x, true
+}
+
+// GetOrSet returns the existing value for the key if present and not expired.
+// Otherwise, it sets x with the given duration and returns it. The loaded result
+// is true if the value was found, false if it was set.
+func (c *anyCache[T]) GetOrSet(k string, x T, d time.Duration) (actual T, loaded bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if v, found := c.get(k); found {
+		return v, true
+	}
+	c.set(k, x, d)
+	return x, false
+}
+
+// GetAndDelete deletes the item for the given key and returns its value, and a
+// bool indicating whether the key was found. The eviction callback is called
+// if the key existed, even if the item had expired.
+func (c *anyCache[T]) GetAndDelete(k string) (T, bool) {
+	c.mu.Lock()
+	v, found := c.get(k)
+	ov, evicted := c.delete(k)
+	c.unlock()
+
+	if evicted {
+		c.evicted(k, ov, deletionReason(found))
+	}
+	if !found {
+		var ret T
+		return ret, false
+	}
+	return v, true
+}
+
+// Swap sets x for the given key with the given duration and returns the
+// previous value, and a bool indicating whether the key existed and hadn't
+// expired.
+func (c *anyCache[T]) Swap(k string, x T, d time.Duration) (old T, existed bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	old, existed = c.get(k)
+	if !existed {
+		var zero T
+		old = zero
+	}
+	c.set(k, x, d)
+	return old, existed
+}
+
+// CompareAndSwapFunc sets new for the given key, keeping its expiration, only
+// if the key exists, hasn't expired and eq reports its current value as equal
+// to old. It returns whether the value was swapped. See CompareAndSwap for
+// comparable types.
+func (c *anyCache[T]) CompareAndSwapFunc(k string, old, new T, eq func(a, b T) bool) bool {
+	c.mu.Lock()
+	defer c.unlock()
+
+	cur, found := c.get(k)
+	if !found || !eq(cur, old) {
+		return false
+	}
+	c.replaceObject(k, new)
+	return true
+}
+
+// CompareAndSwap sets new for the given key, keeping its expiration, only if the
+// key exists, hasn't expired and its current value is equal to old. It returns
+// whether the value was swapped.
+func CompareAndSwap[T comparable](c interface {
+	CompareAndSwapFunc(k string, old, new T, eq func(a, b T) bool) bool
+}, k string, old, new T) bool {
+	return c.CompareAndSwapFunc(k, old, new, func(a, b T) bool {
+		return a == b
+	})
+}
+
+// replaceObject replaces the value of an existing item, keeping its expiration.
+// The cache must be locked.
+func (c *anyCache[T]) replaceObject(k string, x T) {
+	item := c.items[k]
+	item.Object = x
+	c.updateItem(k, item)
+}
+
+// updateItem stores item, an existing item of k whose value was modified, with
+// a new version, counts it as a set and updates the metadata and dependents of
+// the key. The cache must be locked.
+func (c *anyCache[T]) updateItem(k string, item Item[T]) {
+	item.Version = c.nextVersion()
+	c.items[k] = item
+	c.stats.Load().addSets(1)
+	if c.meta != nil {
+		c.touch(k, time.Now().UnixNano())
+	}
+	if c.deps != nil {
+		c.cascade(k)
+	}
+}
+
 // Get gets an item from the cache. Returns the item or nil, and a bool indicating
 // whether the key was found.
 func (c *anyCache[T]) Get(k string) (T, bool) {
+	c.observeKey(k)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	// "Inlining" of get and Expired
 	item, found := c.items[k]
 	if !found {
+		c.countMiss(k)
 		var ret T
 		return ret, false
 	}
 
 	if item.Expiration > 0 {
 		if time.Now().UnixNano() > item.Expiration {
+			c.stats.Load().addMisses(1)
 			var ret T
 			return ret, false
 		}
 	}
 
+	c.stats.Load().addHits(1)
+	if c.meta != nil {
+		c.accessed(k, time.Now().UnixNano())
+	}
 	return item.Object, true
 }
 
+// countMiss counts a read which did not find k in c.items, as a negative hit if
+// k is cached as missing. The cache must be locked.
+func (c *anyCache[T]) countMiss(k string) {
+	if c.missing != nil {
+		if e, found := c.missing[k]; found && (e <= 0 || time.Now().UnixNano() <= e) {
+			c.stats.Load().addNegativeHits(1)
+			return
+		}
+	}
+	c.stats.Load().addMisses(1)
+}
+
+// GetMany gets the given keys from the cache under a single read lock. Keys
+// which are not found or have expired are absent from the returned map.
+func (c *anyCache[T]) GetMany(ks []string) map[string]T {
+	m, _ := c.getMany(ks)
+	return m
+}
+
+// getMany returns the items found for ks and the keys which are neither found
+// nor cached as missing.
+func (c *anyCache[T]) getMany(ks []string) (map[string]T, []string) {
+	m := make(map[string]T, len(ks))
+	var misses []string
+	var negativeHits uint64
+	now := time.Now().UnixNano()
+
+	if c.topK.Load() != nil {
+		for _, k := range ks {
+			c.observeKey(k)
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, k := range ks {
+		x, r := c.lookup(k, now)
+		switch r {
+		case Hit:
+			m[k] = x
+		case Miss:
+			misses = append(misses, k)
+		case NegativeHit:
+			negativeHits++
+		}
+	}
+
+	c.stats.Load().addHits(uint64(len(m)))
+	c.stats.Load().addMisses(uint64(len(misses)))
+	c.stats.Load().addNegativeHits(negativeHits)
+
+	return m, misses
+}
+
 // GetWithExpiration returns an item and its expiration time from the cache.
 // It returns the item or nil, the expiration time if one is set (if the item
 // never expires a zero value for time.Time is returned), and a bool indicating
 // whether the key was found.
 func (c *anyCache[T]) GetWithExpiration(k string) (T, time.Time, bool) {
+	c.observeKey(k)
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	// "Inlining" of get and Expired
 	item, found := c.items[k]
 	if !found {
+		c.countMiss(k)
 		var ret T
 		return ret, time.Time{}, false
 	}
 
 	if item.Expiration > 0 {
 		if time.Now().UnixNano() > item.Expiration {
+			c.stats.Load().addMisses(1)
 			var ret T
 			return ret, time.Time{}, false
 		}
 
 		// Return the item and the expiration time
+		c.stats.Load().addHits(1)
+		if c.meta != nil {
+			c.accessed(k, time.Now().UnixNano())
+		}
 		return item.Object, time.Unix(0, item.Expiration), true
 	}
 
 	// If expiration <= 0 (i.e. no expiration time set) then return the item
 	// and a zeroed time.Time
+	c.stats.Load().addHits(1)
+	if c.meta != nil {
+		c.accessed(k, time.Now().UnixNano())
+	}
 	return item.Object, time.Time{}, true
 }
 
@@ -208,18 +489,18 @@ type numericCache[T Numeric] struct {
 // of the specialized methods, e.g. IncrementInt64.
 func (c *numericCache[T]) Increment(k string, n T) (T, error) {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 
 	v, found := c.items[k]
 
 	if !found || v.Expired() {
 		var ret T
-		return ret, ErrNotFound
+		return ret, keyError(k, ErrNotFound)
 	}
 
 	nv := v.Object + n
 	v.Object = nv
-	c.items[k] = v
+	c.updateItem(k, v)
 
 	return nv, nil
 }
@@ -233,18 +514,18 @@ func (c *numericCache[T]) Decrement(k string, n T) (T, error) {
 	// TODO: Implement Increment and Decrement more cleanly.
 	// (Cannot do Increment(k, n*-1) for uints.)
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 
 	v, found := c.items[k]
 
 	if !found || v.Expired() {
 		var ret T
-		return ret, ErrNotFound
+		return ret, keyError(k, ErrNotFound)
 	}
 
 	nv := v.Object - n
 	v.Object = nv
-	c.items[k] = v
+	c.updateItem(k, v)
 
 	return nv, nil
 }
@@ -253,13 +534,63 @@ func (c *numericCache[T]) Decrement(k string, n T) (T, error) {
 func (c *anyCache[T]) Delete(k string) {
 	c.mu.Lock()
 	v, evicted := c.delete(k)
-	c.mu.Unlock()
+	callbacks := c.evictionCallbacks()
+	c.unlock()
+
+	if evicted {
+		c.stats.Load().addEvictions(EvictionDeleted, 1)
+		c.logEviction(k, EvictionDeleted)
+		callbacks.call(k, v, EvictionDeleted)
+	}
+}
+
+// evictionCallbacks holds the eviction callbacks of a cache, so that they can
+// be read with the cache locked and called once it is unlocked.
+type evictionCallbacks[T any] struct {
+	onEvicted           func(string, T)
+	onEvictedWithReason func(string, T, EvictionReason)
+}
+
+// evictionCallbacks returns the eviction callbacks. The cache must be locked.
+func (c *anyCache[T]) evictionCallbacks() evictionCallbacks[T] {
+	return evictionCallbacks[T]{c.onEvicted, c.onEvictedWithReason}
+}
 
-	if c.onEvicted != nil && evicted {
-		c.onEvicted(k, v)
+// enabled reports whether there is a callback to call, so that callers can
+// avoid collecting evicted items otherwise.
+func (cb evictionCallbacks[T]) enabled() bool {
+	return cb.onEvicted != nil || cb.onEvictedWithReason != nil
+}
+
+func (cb evictionCallbacks[T]) call(k string, v T, reason EvictionReason) {
+	if cb.onEvicted != nil {
+		cb.onEvicted(k, v)
+	}
+	if cb.onEvictedWithReason != nil {
+		cb.onEvictedWithReason(k, v, reason)
 	}
 }
 
+// evicted records the eviction of k and calls the eviction callbacks. The cache
+// must not be locked.
+func (c *anyCache[T]) evicted(k string, v T, reason EvictionReason) {
+	c.stats.Load().addEvictions(reason, 1)
+	c.logEviction(k, reason)
+	c.mu.RLock()
+	callbacks := c.evictionCallbacks()
+	c.mu.RUnlock()
+	callbacks.call(k, v, reason)
+}
+
+// deletionReason returns the reason of the deletion of an item which was found
+// unexpired or not.
+func deletionReason(found bool) EvictionReason {
+	if found {
+		return EvictionDeleted
+	}
+	return EvictionExpired
+}
+
 func (c *anyCache[T]) delete(k string) (T, bool) {
 	var found = false
 	var ret T
@@ -269,10 +600,99 @@ func (c *anyCache[T]) delete(k string) (T, bool) {
 		ret = v.Object
 		delete(c.items, k)
 	}
+	if c.missing != nil {
+		delete(c.missing, k)
+	}
+	if c.meta != nil {
+		delete(c.meta, k)
+	}
+	if c.index != nil && found {
+		c.index.delete(k)
+	}
+	if c.tags != nil {
+		c.tags.untag(k)
+	}
+	if c.deps != nil {
+		c.deps.remove(k)
+		c.cascade(k)
+	}
 
 	return ret, found
 }
 
+// DeleteMany deletes the given keys from the cache under a single lock and
+// returns the number of items deleted. Keys which are not in the cache are
+// ignored. Items which had already expired are deleted too but, as with
+// DeleteExpired, their evictions have the reason EvictionExpired and they are
+// not counted.
+func (c *anyCache[T]) DeleteMany(ks []string) int {
+	return c.deleteKeysFunc(func() []string {
+		return ks
+	})
+}
+
+// DeleteFunc deletes all the items for which f returns true under a single lock
+// and returns the number of items deleted. f is called for every item in the
+// cache, including expired items which have not yet been cleaned up, which are
+// handled as in DeleteMany, and must not call the cache's methods.
+func (c *anyCache[T]) DeleteFunc(f func(k string, v T) bool) int {
+	return c.deleteKeysFunc(func() []string {
+		var ks []string
+		for k, v := range c.items {
+			if f(k, v.Object) {
+				ks = append(ks, k)
+			}
+		}
+		return ks
+	})
+}
+
+// deleteKeysFunc deletes the keys returned by keys, which is called with the
+// cache locked, under a single lock and returns the number of items deleted.
+// Items which had already expired are deleted as well, but their evictions are
+// reported with the reason EvictionExpired and they are not counted.
+func (c *anyCache[T]) deleteKeysFunc(keys func() []string) int {
+	var evictedItems []evictedItem[T]
+	logEvictions := c.logsEvictions()
+	var counts [evictionReasons]uint64
+	c.mu.Lock()
+	callbacks := c.evictionCallbacks()
+	now := time.Now().UnixNano()
+	for _, k := range keys() {
+		// "Inlining" of Expired
+		item, found := c.items[k]
+		if !found {
+			c.delete(k)
+			continue
+		}
+		reason := deletionReason(item.Expiration <= 0 || now <= item.Expiration)
+		c.delete(k)
+		counts[reason]++
+		if logEvictions || callbacks.enabled() {
+			evictedItems = append(evictedItems, evictedItem[T]{k, item.Object, reason})
+		}
+	}
+	c.unlock()
+	for reason, n := range counts {
+		c.stats.Load().addEvictions(EvictionReason(reason), n)
+	}
+	for _, v := range evictedItems {
+		c.logEviction(v.key, v.reason)
+	}
+	for _, v := range evictedItems {
+		callbacks.call(v.key, v.value, v.reason)
+	}
+	return int(counts[EvictionDeleted])
+}
+
+// evictedItem is an item evicted from the cache along with the reason of its
+// eviction.
+type evictedItem[T any] struct {
+	key    string
+	value  T
+	reason EvictionReason
+}
+
 type keyAndValue[T any] struct {
 	key   string
 	value T
@@ -281,20 +701,42 @@ type keyAndValue[T any] struct {
 // DeleteExpired deletes all expired items from the cache.
 func (c *anyCache[T]) DeleteExpired() {
 	var evictedItems []keyAndValue[T]
-	now := time.Now().UnixNano()
+	var expiredKeys []string
+	var expired uint64
+	logEvictions := c.logsEvictions()
+	start := time.Now()
+	now := start.UnixNano()
 	c.mu.Lock()
+	callbacks := c.evictionCallbacks()
+	scanned := len(c.items)
 	for k, v := range c.items {
 		// "Inlining" of expired
 		if v.Expiration > 0 && now > v.Expiration {
 			ov, evicted := c.delete(k)
-			if c.onEvicted != nil && evicted {
+			expired++
+			if logEvictions {
+				expiredKeys = append(expiredKeys, k)
+			}
+			if callbacks.enabled() && evicted {
 				evictedItems = append(evictedItems, keyAndValue[T]{k, ov})
 			}
 		}
 	}
-	c.mu.Unlock()
+	for k, e := range c.missing {
+		if e > 0 && now > e {
+			delete(c.missing, k)
+		}
+	}
+	c.unlock()
+	c.stats.Load().addEvictions(EvictionExpired, expired)
+	d := time.Since(start)
+	c.stats.Load().recordSweep(d)
+	c.logSweep(scanned, int(expired), d)
+	for _, k := range expiredKeys {
+		c.logEviction(k, EvictionExpired)
+	}
 	for _, v := range evictedItems {
-		c.onEvicted(v.key, v.value)
+		callbacks.call(v.key, v.value, EvictionExpired)
 	}
 }
 
@@ -312,10 +754,21 @@ func (c *anyCache[T]) setJanitor(j *janitor[T]) {
 func (c *anyCache[T]) OnEvicted(f func(string, T)) {
 	c.mu.Lock()
 	c.onEvicted = f
-	c.mu.Unlock()
+	c.unlock()
+}
+
+// OnEvictedWithReason sets an (optional) function that is called like the one
+// set with OnEvicted, along with it, with the reason of the eviction. Set to nil
+// to disable.
+func (c *anyCache[T]) OnEvictedWithReason(f func(string, T, EvictionReason)) {
+	c.mu.Lock()
+	c.onEvictedWithReason = f
+	c.unlock()
 }
 
 // Items copies all unexpired items in the cache into a new map and returns it.
+// Its signature is part of the AnyCacher interface, so the items do not carry
+// their metadata; use ItemInfos to get it.
 func (c *anyCache[T]) Items() map[string]Item[T] {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -345,8 +798,19 @@ func (c *anyCache[T]) ItemCount() int {
 // Flush Delete all items from the cache.
 func (c *anyCache[T]) Flush() {
 	c.mu.Lock()
+	n := len(c.items)
 	c.items = map[string]Item[T]{}
-	c.mu.Unlock()
+	c.missing = nil
+	if c.meta != nil {
+		c.meta = map[string]*itemMeta{}
+	}
+	if c.index != nil {
+		c.index = newKeyIndex()
+	}
+	c.tags = nil
+	c.deps = nil
+	c.unlock()
+	c.stats.Load().addEvictions(EvictionFlushed, uint64(n))
 }
 
 type janitor[T any] struct {
@@ -393,6 +857,7 @@ func newAnyCache[T any](de time.Duration, m map[string]Item[T]) *anyCache[T] {
 		defaultExpiration: de,
 		items:             m,
 	}
+	c.initVersions()
 	return c
 }
 
@@ -404,6 +869,7 @@ func newNumericCache[T Numeric](de time.Duration, m map[string]Item[T]) *numeric
 		defaultExpiration: de,
 		items:             m,
 	}
+	c.initVersions()
 	nc := &numericCache[T]{c}
 	return nc
 }
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"math/rand"
+	"reflect"
 	"runtime"
 	"strconv"
 	"sync"
@@ -1148,6 +1149,71 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+func TestSetMany(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	n := tc.SetMany(map[string]int{"foo": 1, "bar": 2}, NoExpiration)
+	if n != 2 {
+		t.Error("SetMany did not return 2:", n)
+	}
+	x, found := tc.Get("foo")
+	if !found || x != 1 {
+		t.Error("foo was not set:", x)
+	}
+	x, found = tc.Get("bar")
+	if !found || x != 2 {
+		t.Error("bar was not set:", x)
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetMany(map[string]int{"foo": 1, "bar": 2, "baz": 3}, DefaultExpiration)
+	var evicted []string
+	tc.OnEvicted(func(k string, v int) {
+		evicted = append(evicted, k)
+	})
+	n := tc.DeleteMany([]string{"foo", "bar", "qux"})
+	if n != 2 {
+		t.Error("DeleteMany did not return 2:", n)
+	}
+	if len(evicted) != 2 {
+		t.Error("onEvicted was not called twice:", evicted)
+	}
+	if _, found := tc.Get("baz"); !found {
+		t.Error("baz was deleted")
+	}
+
+	tc.EnableStats()
+	tc.Set("expired", 1, time.Nanosecond)
+	<-time.After(time.Millisecond)
+	if n := tc.DeleteMany([]string{"baz", "expired"}); n != 1 {
+		t.Error("DeleteMany counted an expired item:", n)
+	}
+	if st := tc.Stats(); st.Deletes != 1 || st.Expirations != 1 || tc.ItemCount() != 0 {
+		t.Error("unexpected evictions:", st.Evictions, tc.ItemCount())
+	}
+}
+
+func TestDeleteFunc(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	for i := 0; i < 10; i++ {
+		tc.Set(strconv.Itoa(i), i, DefaultExpiration)
+	}
+	evicted := 0
+	tc.OnEvicted(func(k string, v int) {
+		evicted++
+	})
+	n := tc.DeleteFunc(func(k string, v int) bool {
+		return v%2 == 0
+	})
+	if n != 5 || evicted != 5 {
+		t.Error("DeleteFunc did not delete 5 items:", n, evicted)
+	}
+	if n := tc.ItemCount(); n != 5 {
+		t.Errorf("Item count is not 5: %d", n)
+	}
+}
+
 func TestItemCount(t *testing.T) {
 	tc := New[any](DefaultExpiration, 0)
 	tc.Set("foo", "1", DefaultExpiration)
@@ -1241,6 +1307,34 @@ func TestOnEvicted(t *testing.T) {
 	}
 }
 
+func TestOnEvictedWithReason(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	reasons := map[string]EvictionReason{}
+	tc.OnEvictedWithReason(func(k string, v int, reason EvictionReason) {
+		reasons[k] = reason
+	})
+	tc.Set("deleted", 1, DefaultExpiration)
+	tc.Set("many", 2, DefaultExpiration)
+	tc.Set("expired", 3, time.Nanosecond)
+	tc.Set("updated", 4, DefaultExpiration)
+	<-time.After(time.Millisecond)
+
+	tc.Delete("deleted")
+	tc.DeleteMany([]string{"many"})
+	tc.Update("updated", func(old int, exists bool) (int, bool) { return 0, false })
+	tc.DeleteExpired()
+
+	want := map[string]EvictionReason{
+		"deleted": EvictionDeleted,
+		"many":    EvictionDeleted,
+		"expired": EvictionExpired,
+		"updated": EvictionDeleted,
+	}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Error("unexpected eviction reasons:", reasons)
+	}
+}
+
 func TestFinalizerNew(t *testing.T) {
 	defer goleak.VerifyNone(t)
 	defer runtime.GC() // Force gc before verifying there are no leaked goroutines
@@ -1544,6 +1638,19 @@ func BenchmarkRWMutexMapSetDeleteSingleLock(b *testing.B) {
 	}
 }
 
+func BenchmarkCacheSetMany(b *testing.B) {
+	b.StopTimer()
+	items := make(map[string]string, 1000)
+	for i := 0; i < 1000; i++ {
+		items[strconv.Itoa(i)] = "bar"
+	}
+	tc := New[string](DefaultExpiration, 0)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tc.SetMany(items, DefaultExpiration)
+	}
+}
+
 func BenchmarkIncrementInt(b *testing.B) {
 	b.StopTimer()
 	tc := NewNumeric[int64](DefaultExpiration, 0)
@@ -1660,3 +1767,145 @@ func TestGetWithExpiration(t *testing.T) {
 		t.Error("expiration for e is in the past")
 	}
 }
+
+func TestUpdate(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	incr := func(old int, exists bool) (int, bool) {
+		return old + 1, true
+	}
+
+	x, kept := tc.Update("foo", incr)
+	if x != 1 || !kept {
+		t.Error("Update did not create foo:", x, kept)
+	}
+
+	tc.Set("bar", 1, 50*time.Millisecond)
+	_, e1, _ := tc.GetWithExpiration("bar")
+	tc.Update("bar", incr)
+	x, e2, _ := tc.GetWithExpiration("bar")
+	if x != 2 || !e1.Equal(e2) {
+		t.Error("Update did not keep bar's expiration:", x, e1, e2)
+	}
+
+	evicted := ""
+	tc.OnEvicted(func(k string, v int) {
+		evicted = k
+	})
+	x, kept = tc.Update("foo", func(old int, exists bool) (int, bool) {
+		if !exists || old != 1 {
+			t.Error("Update was called with unexpected arguments:", old, exists)
+		}
+		return 0, false
+	})
+	if kept {
+		t.Error("Update kept foo")
+	}
+	if _, found := tc.Get("foo"); found || evicted != "foo" {
+		t.Error("Update did not delete foo")
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tc.Update("counter", incr)
+		}()
+	}
+	wg.Wait()
+	if x, _ := tc.Get("counter"); x != 100 {
+		t.Error("concurrent updates were lost:", x)
+	}
+}
+
+func TestGetOrSet(t *testing.T) {
+	tc := New[string](DefaultExpiration, 0)
+	x, loaded := tc.GetOrSet("foo", "bar", DefaultExpiration)
+	if x != "bar" || loaded {
+		t.Error("GetOrSet did not set foo:", x, loaded)
+	}
+	x, loaded = tc.GetOrSet("foo", "baz", DefaultExpiration)
+	if x != "bar" || !loaded {
+		t.Error("GetOrSet did not load foo:", x, loaded)
+	}
+
+	tc.Set("expired", "old", time.Nanosecond)
+	<-time.After(time.Millisecond)
+	x, loaded = tc.GetOrSet("expired", "new", DefaultExpiration)
+	if x != "new" || loaded {
+		t.Error("GetOrSet loaded an expired item:", x, loaded)
+	}
+}
+
+func TestGetAndDelete(t *testing.T) {
+	tc := New[string](DefaultExpiration, 0)
+	tc.Set("foo", "bar", DefaultExpiration)
+	x, found := tc.GetAndDelete("foo")
+	if x != "bar" || !found {
+		t.Error("GetAndDelete did not return foo:", x, found)
+	}
+	if _, found := tc.Get("foo"); found {
+		t.Error("foo was not deleted")
+	}
+	if _, found := tc.GetAndDelete("foo"); found {
+		t.Error("GetAndDelete found a deleted item")
+	}
+}
+
+func TestSwap(t *testing.T) {
+	tc := New[string](DefaultExpiration, 0)
+	old, existed := tc.Swap("foo", "bar", DefaultExpiration)
+	if old != "" || existed {
+		t.Error("Swap returned a previous value:", old, existed)
+	}
+	old, existed = tc.Swap("foo", "baz", DefaultExpiration)
+	if old != "bar" || !existed {
+		t.Error("Swap did not return the previous value:", old, existed)
+	}
+	if x, _ := tc.Get("foo"); x != "baz" {
+		t.Error("foo was not swapped:", x)
+	}
+}
+
+func TestCompareAndSwap(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+	if CompareAndSwap[int](tc, "foo", 0, 1) {
+		t.Error("swapped a missing key")
+	}
+	tc.Set("foo", 1, DefaultExpiration)
+	if CompareAndSwap[int](tc, "foo", 2, 3) {
+		t.Error("swapped a different value")
+	}
+	if !CompareAndSwap[int](tc, "foo", 1, 2) {
+		t.Error("did not swap an equal value")
+	}
+	if x, _ := tc.Get("foo"); x != 2 {
+		t.Error("foo was not swapped:", x)
+	}
+
+	ts := New[[]string](DefaultExpiration, 0)
+	ts.Set("foo", []string{"a"}, DefaultExpiration)
+	eq := func(a, b []string) bool {
+		return len(a) == len(b) && (len(a) == 0 || a[0] == b[0])
+	}
+	if !ts.CompareAndSwapFunc("foo", []string{"a"}, []string{"b"}, eq) {
+		t.Error("did not swap an equal slice")
+	}
+
+	wg := sync.WaitGroup{}
+	swapped := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if CompareAndSwap[int](tc, "foo", 2, 10+i) {
+				swapped <- strconv.Itoa(i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(swapped)
+	if n := len(swapped); n != 1 {
+		t.Error("more than one concurrent swap succeeded:", n)
+	}
+}
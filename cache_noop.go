@@ -1,6 +1,9 @@
 package cache
 
 import (
+	"context"
+	"iter"
+	"log/slog"
 	"time"
 )
 
@@ -14,6 +17,12 @@ func (c *NoopCache[T]) Set(k string, x T, d time.Duration) {
 
 }
 
+// SetMany adds all the given items to the cache, replacing any existing ones,
+// and returns the number of items set.
+func (c *NoopCache[T]) SetMany(items map[string]T, d time.Duration) int {
+	return 0
+}
+
 // SetDefault adds an item to the cache, replacing any existing item, using the default
 // expiration.
 func (c *NoopCache[T]) SetDefault(k string, x T) {
@@ -21,15 +30,16 @@ func (c *NoopCache[T]) SetDefault(k string, x T) {
 }
 
 // Add an item to the cache only if an item doesn't already exist for the given
-// key, or if the existing item has expired. Returns an error otherwise.
+// key, or if the existing item has expired. As no item ever exists, it always
+// succeeds.
 func (c *NoopCache[T]) Add(k string, x T, d time.Duration) error {
 	return nil
 }
 
 // Replace replaces a new value for the cache key only if it already exists, and the existing
-// item hasn't expired. Returns an error otherwise.
+// item hasn't expired. As no item ever exists, it always returns ErrNotFound.
 func (c *NoopCache[T]) Replace(k string, x T, d time.Duration) error {
-	return nil
+	return keyError(k, ErrNotFound)
 }
 
 // Get gets an item from the cache. Returns the item or nil, and a bool indicating
@@ -39,6 +49,54 @@ func (c *NoopCache[T]) Get(k string) (T, bool) {
 	return ret, false
 }
 
+// GetMany gets the given keys from the cache. Keys which are not found or have
+// expired are absent from the returned map.
+func (c *NoopCache[T]) GetMany(ks []string) map[string]T {
+	return make(map[string]T)
+}
+
+// GetManyOrLoad gets the given keys from the cache and loads the missing ones
+// using load.
+func (c *NoopCache[T]) GetManyOrLoad(ctx context.Context, ks []string, load BatchLoaderFunc[T]) (map[string]T, error) {
+	if len(ks) == 0 {
+		return make(map[string]T), nil
+	}
+	m, err := load(ctx, ks)
+	if m == nil {
+		m = make(map[string]T)
+	}
+	return m, err
+}
+
+// SetBatchWindow sets the time GetManyOrLoad waits for concurrent callers to
+// add their missing keys to a batch before calling the batch loader.
+func (c *NoopCache[T]) SetBatchWindow(d time.Duration) {
+
+}
+
+// SetBatchTimeout sets the time the batch loader is given to load a batch.
+func (c *NoopCache[T]) SetBatchTimeout(d time.Duration) {
+
+}
+
+// Lookup gets an item from the cache. It returns the item, or the zero value
+// of T, and whether the key was found, cached as missing or not cached at all.
+func (c *NoopCache[T]) Lookup(k string) (T, LookupResult) {
+	var ret T
+	return ret, Miss
+}
+
+// SetMissing caches k as known to be absent, replacing any existing item for
+// the key.
+func (c *NoopCache[T]) SetMissing(k string, d time.Duration) {
+
+}
+
+// SetMissingExpiration sets the default expiration of negative entries.
+func (c *NoopCache[T]) SetMissingExpiration(d time.Duration) {
+
+}
+
 // GetWithExpiration returns an item and its expiration time from the cache.
 // It returns the item or nil, the expiration time if one is set (if the item
 // never expires a zero value for time.Time is returned), and a bool indicating
@@ -60,7 +118,7 @@ type NoopNumericCache[T Numeric] struct {
 // of the specialized methods, e.g. IncrementInt64.
 func (c *NoopNumericCache[T]) Increment(k string, n T) (T, error) {
 	var ret T
-	return ret, ErrNotFound
+	return ret, keyError(k, ErrNotFound)
 }
 
 // Decrement decrements an item of type int, int8, int16, int32, int64, uintptr, uint,
@@ -70,7 +128,129 @@ func (c *NoopNumericCache[T]) Increment(k string, n T) (T, error) {
 // of the specialized methods, e.g. DecrementInt64.
 func (c *NoopNumericCache[T]) Decrement(k string, n T) (T, error) {
 	var ret T
-	return ret, ErrNotFound
+	return ret, keyError(k, ErrNotFound)
+}
+
+// IncrementChecked increments an item by n, returning ErrOverflow if the
+// result overflows its type.
+func (c *NoopNumericCache[T]) IncrementChecked(k string, n T) (T, error) {
+	var ret T
+	return ret, keyError(k, ErrNotFound)
+}
+
+// DecrementChecked decrements an item by n, returning ErrOverflow if the
+// result overflows its type.
+func (c *NoopNumericCache[T]) DecrementChecked(k string, n T) (T, error) {
+	var ret T
+	return ret, keyError(k, ErrNotFound)
+}
+
+// IncrementSaturating increments an item by n, clamping the result to the
+// bounds of its type.
+func (c *NoopNumericCache[T]) IncrementSaturating(k string, n T) (T, error) {
+	var ret T
+	return ret, keyError(k, ErrNotFound)
+}
+
+// DecrementSaturating decrements an item by n, clamping the result to the
+// bounds of its type.
+func (c *NoopNumericCache[T]) DecrementSaturating(k string, n T) (T, error) {
+	var ret T
+	return ret, keyError(k, ErrNotFound)
+}
+
+// IncrementBounded increments an item by n and clamps the result to
+// [min, max].
+func (c *NoopNumericCache[T]) IncrementBounded(k string, n, min, max T) (T, error) {
+	var ret T
+	return ret, keyError(k, ErrNotFound)
+}
+
+// DecrementBounded decrements an item by n and clamps the result to
+// [min, max].
+func (c *NoopNumericCache[T]) DecrementBounded(k string, n, min, max T) (T, error) {
+	var ret T
+	return ret, keyError(k, ErrNotFound)
+}
+
+// IncrementOrSet atomically increments an item by n, or sets it to n. As no item ever
+// exists, it returns n.
+func (c *NoopNumericCache[T]) IncrementOrSet(k string, n T, d time.Duration) T {
+	return n
+}
+
+// IncrementOrSetRefresh atomically increments an item by n, or sets it to n. As no item ever
+// exists, it returns n.
+func (c *NoopNumericCache[T]) IncrementOrSetRefresh(k string, n T, d time.Duration) T {
+	return n
+}
+
+// DecrementOrSet atomically decrements an item by n, or sets it to n. As no item ever
+// exists, it returns n.
+func (c *NoopNumericCache[T]) DecrementOrSet(k string, n T, d time.Duration) T {
+	return n
+}
+
+// DecrementOrSetRefresh atomically decrements an item by n, or sets it to n. As no item ever
+// exists, it returns n.
+func (c *NoopNumericCache[T]) DecrementOrSetRefresh(k string, n T, d time.Duration) T {
+	return n
+}
+
+// Multiply multiplies an item by n.
+func (c *NoopNumericCache[T]) Multiply(k string, n T) (T, error) {
+	var ret T
+	return ret, keyError(k, ErrNotFound)
+}
+
+// Divide divides an item by n.
+func (c *NoopNumericCache[T]) Divide(k string, n T) (T, error) {
+	var ret T
+	if n == 0 {
+		return ret, keyError(k, ErrDivisionByZero)
+	}
+	return ret, keyError(k, ErrNotFound)
+}
+
+// SetMax sets an item to x if x is greater than its current value. As no item
+// ever exists, it returns x and true.
+func (c *NoopNumericCache[T]) SetMax(k string, x T, d time.Duration) (T, bool) {
+	return x, true
+}
+
+// SetMin sets an item to x if x is lower than its current value. As no item
+// ever exists, it returns x and true.
+func (c *NoopNumericCache[T]) SetMin(k string, x T, d time.Duration) (T, bool) {
+	return x, true
+}
+
+// GetAndReset returns the zero value of T and false.
+func (c *NoopNumericCache[T]) GetAndReset(k string) (T, bool) {
+	var ret T
+	return ret, false
+}
+
+// Sum returns the zero value of T.
+func (c *NoopNumericCache[T]) Sum(prefix string) T {
+	var ret T
+	return ret
+}
+
+// Min returns the zero value of T and false.
+func (c *NoopNumericCache[T]) Min(prefix string) (T, bool) {
+	var ret T
+	return ret, false
+}
+
+// Max returns the zero value of T and false.
+func (c *NoopNumericCache[T]) Max(prefix string) (T, bool) {
+	var ret T
+	return ret, false
+}
+
+// Mean returns 0 and false.
+func (c *NoopNumericCache[T]) Mean(prefix string) (float64, bool) {
+	return 0, false
 }
 
 // Delete deletes an item from the cache. Does nothing if the key is not in the cache.
@@ -78,6 +258,18 @@ func (c *NoopCache[T]) Delete(k string) {
 
 }
 
+// DeleteMany deletes the given keys from the cache and returns the number of
+// items deleted.
+func (c *NoopCache[T]) DeleteMany(ks []string) int {
+	return 0
+}
+
+// DeleteFunc deletes all the items for which f returns true and returns the
+// number of items deleted.
+func (c *NoopCache[T]) DeleteFunc(f func(k string, v T) bool) int {
+	return 0
+}
+
 // DeleteExpired deletes all expired items from the cache.
 func (c *NoopCache[T]) DeleteExpired() {
 
@@ -90,12 +282,191 @@ func (c *NoopCache[T]) OnEvicted(f func(string, T)) {
 
 }
 
+// OnEvictedWithReason sets an (optional) function that is called with the key,
+// value and reason when an item is evicted from the cache.
+func (c *NoopCache[T]) OnEvictedWithReason(f func(string, T, EvictionReason)) {
+
+}
+
+// SetLogger sets an (optional) logger the cache emits structured records to.
+func (c *NoopCache[T]) SetLogger(l *slog.Logger) {
+
+}
+
+// SetLogRateLimit sets the maximum number of records per second the cache logs
+// for each kind of event.
+func (c *NoopCache[T]) SetLogRateLimit(n int) {
+
+}
+
+// GetWithVersion returns the zero value of T, 0 and false.
+func (c *NoopCache[T]) GetWithVersion(k string) (T, uint64, bool) {
+	var zero T
+	return zero, 0, false
+}
+
+// SetIfVersion returns nil if version is 0, as for Add, and ErrNotFound
+// otherwise.
+func (c *NoopCache[T]) SetIfVersion(k string, x T, d time.Duration, version uint64) error {
+	if version != 0 {
+		return keyError(k, ErrNotFound)
+	}
+	return nil
+}
+
+// Update calls f with the zero value of T and returns its result, nothing is
+// stored.
+func (c *NoopCache[T]) Update(k string, f func(old T, exists bool) (new T, keep bool)) (T, bool) {
+	var zero T
+	return f(zero, false)
+}
+
+// GetOrSet returns x and false.
+func (c *NoopCache[T]) GetOrSet(k string, x T, d time.Duration) (actual T, loaded bool) {
+	return x, false
+}
+
+// GetAndDelete returns the zero value of T and false.
+func (c *NoopCache[T]) GetAndDelete(k string) (T, bool) {
+	var zero T
+	return zero, false
+}
+
+// Swap returns the zero value of T and false.
+func (c *NoopCache[T]) Swap(k string, x T, d time.Duration) (old T, existed bool) {
+	var zero T
+	return zero, false
+}
+
+// CompareAndSwapFunc returns false.
+func (c *NoopCache[T]) CompareAndSwapFunc(k string, old, new T, eq func(a, b T) bool) bool {
+	return false
+}
+
+// Range does nothing as the cache is always empty.
+func (c *NoopCache[T]) Range(f func(k string, v T) bool) {
+
+}
+
+// RangeChunked does nothing as the cache is always empty.
+func (c *NoopCache[T]) RangeChunked(size int, f func(k string, v T) bool) {
+
+}
+
+// All returns an empty iterator.
+func (c *NoopCache[T]) All() iter.Seq2[string, T] {
+	return c.Range
+}
+
+// AllChunked returns an empty iterator.
+func (c *NoopCache[T]) AllChunked(size int) iter.Seq2[string, T] {
+	return c.Range
+}
+
+// Keys returns an empty slice.
+func (c *NoopCache[T]) Keys() []string {
+	return []string{}
+}
+
+// Values returns an empty slice.
+func (c *NoopCache[T]) Values() []T {
+	return []T{}
+}
+
+// EnableKeyIndex enables the index of the keys of the cache.
+func (c *NoopCache[T]) EnableKeyIndex() {
+
+}
+
+// KeysByPrefix returns nil.
+func (c *NoopCache[T]) KeysByPrefix(prefix string) []string {
+	return nil
+}
+
+// CountByPrefix returns 0.
+func (c *NoopCache[T]) CountByPrefix(prefix string) int {
+	return 0
+}
+
+// DeleteByPrefix returns 0.
+func (c *NoopCache[T]) DeleteByPrefix(prefix string) int {
+	return 0
+}
+
+// KeysMatching returns nil.
+func (c *NoopCache[T]) KeysMatching(pattern string) []string {
+	return nil
+}
+
+// CountMatching returns 0.
+func (c *NoopCache[T]) CountMatching(pattern string) int {
+	return 0
+}
+
+// DeleteMatching returns 0.
+func (c *NoopCache[T]) DeleteMatching(pattern string) int {
+	return 0
+}
+
+// SetWithDependencies adds an item to the cache along with dependencies.
+func (c *NoopCache[T]) SetWithDependencies(k string, x T, d time.Duration, deps ...string) error {
+	return nil
+}
+
+// SetWithTags adds an item to the cache along with tags.
+func (c *NoopCache[T]) SetWithTags(k string, x T, d time.Duration, tags ...string) {
+
+}
+
+// InvalidateTag returns 0.
+func (c *NoopCache[T]) InvalidateTag(tag string) int {
+	return 0
+}
+
+// Tags returns nil.
+func (c *NoopCache[T]) Tags(k string) []string {
+	return nil
+}
+
+// KeysForTag returns nil.
+func (c *NoopCache[T]) KeysForTag(tag string) []string {
+	return nil
+}
+
 // Items copies all unexpired items in the cache into a new map and returns it.
 func (c *NoopCache[T]) Items() map[string]Item[T] {
 	m := make(map[string]Item[T], 0)
 	return m
 }
 
+// TrackTopKeys enables the tracking of the most frequently read and written
+// keys.
+func (c *NoopCache[T]) TrackTopKeys(capacity int, decay time.Duration) {
+
+}
+
+// TopKeys returns the n most frequently read and written keys.
+func (c *NoopCache[T]) TopKeys(n int) []KeyCount {
+	return nil
+}
+
+// EnableMetadata enables the tracking of the metadata of items.
+func (c *NoopCache[T]) EnableMetadata() {
+
+}
+
+// GetItem returns an unexpired item along with its metadata, and a bool
+// indicating whether the key was found.
+func (c *NoopCache[T]) GetItem(k string) (ItemInfo[T], bool) {
+	return ItemInfo[T]{}, false
+}
+
+// ItemInfos copies all unexpired items in the cache along with their metadata
+// into a new map and returns it.
+func (c *NoopCache[T]) ItemInfos() map[string]ItemInfo[T] {
+	return make(map[string]ItemInfo[T])
+}
+
 // ItemCount returns the number of items in the cache. This may include items that have
 // expired, but have not yet been cleaned up.
 func (c *NoopCache[T]) ItemCount() int {
@@ -107,6 +478,21 @@ func (c *NoopCache[T]) Flush() {
 
 }
 
+// EnableStats enables the statistics of the cache.
+func (c *NoopCache[T]) EnableStats() {
+
+}
+
+// Stats returns the statistics of the cache.
+func (c *NoopCache[T]) Stats() Stats {
+	return Stats{}
+}
+
+// ResetStats resets all the statistics of the cache to zero.
+func (c *NoopCache[T]) ResetStats() {
+
+}
+
 func newNoopCache[T any]() *NoopCache[T] {
 	return &NoopCache[T]{}
 }
@@ -1,16 +1,11 @@
 package cache
 
 import (
-	"errors"
 	"time"
 
 	"golang.org/x/exp/constraints"
 )
 
-var (
-	ErrNotFound = errors.New("item not found")
-)
-
 type Numeric interface {
 	constraints.Integer | constraints.Float
 }
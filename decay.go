@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"math"
+	"time"
+
+	"golang.org/x/exp/constraints"
+)
+
+// DecayingCache holds scores which decay exponentially over time: a score
+// loses half of its value every half-life. The decay is computed lazily from
+// the time a score was last updated, so reading a score returns its current
+// value without any background work.
+//
+// Scores are stored in an AnyCache, they expire and are deleted by its janitor
+// like any other item.
+type DecayingCache[T constraints.Float] struct {
+	c        *AnyCache[decayingScore[T]]
+	halfLife time.Duration
+	now      func() time.Time
+}
+
+// decayingScore is a score along with the time it was last updated.
+type decayingScore[T constraints.Float] struct {
+	value   T
+	updated int64
+}
+
+// at returns the value of the score decayed at now.
+func (s decayingScore[T]) at(now int64, halfLife time.Duration) T {
+	if halfLife <= 0 || now <= s.updated {
+		return s.value
+	}
+	elapsed := float64(now-s.updated) / float64(halfLife)
+	return T(float64(s.value) * math.Exp2(-elapsed))
+}
+
+// Set sets the score of k to x, replacing any existing one. The duration is
+// handled as in AnyCache.Set.
+func (c *DecayingCache[T]) Set(k string, x T, d time.Duration) {
+	c.c.Set(k, decayingScore[T]{value: x, updated: c.now().UnixNano()}, d)
+}
+
+// Get returns the current decayed score of k, and a bool indicating whether the
+// key was found.
+func (c *DecayingCache[T]) Get(k string) (T, bool) {
+	s, found := c.c.Get(k)
+	if !found {
+		return 0, false
+	}
+	return s.at(c.now().UnixNano(), c.halfLife), true
+}
+
+// Increment decays the score of k and adds n to it, keeping its expiration.
+// Returns the new score, or ErrNotFound if the key was not found or has
+// expired.
+func (c *DecayingCache[T]) Increment(k string, n T) (T, error) {
+	now := c.now().UnixNano()
+
+	c.c.mu.Lock()
+	defer c.c.unlock()
+
+	s, found := c.c.get(k)
+	if !found {
+		return 0, keyError(k, ErrNotFound)
+	}
+	s = decayingScore[T]{value: s.at(now, c.halfLife) + n, updated: now}
+	c.c.replaceObject(k, s)
+	return s.value, nil
+}
+
+// IncrementOrSet decays the score of k and adds n to it, keeping its
+// expiration, or sets it to n with the duration d if the key does not exist or
+// has expired. Returns the new score.
+func (c *DecayingCache[T]) IncrementOrSet(k string, n T, d time.Duration) T {
+	now := c.now().UnixNano()
+
+	c.c.mu.Lock()
+	defer c.c.unlock()
+
+	s, found := c.c.get(k)
+	if !found {
+		c.c.set(k, decayingScore[T]{value: n, updated: now}, d)
+		return n
+	}
+	s = decayingScore[T]{value: s.at(now, c.halfLife) + n, updated: now}
+	c.c.replaceObject(k, s)
+	return s.value
+}
+
+// Delete deletes the score of k. Does nothing if the key is not in the cache.
+func (c *DecayingCache[T]) Delete(k string) {
+	c.c.Delete(k)
+}
+
+// Items returns the current decayed scores of all unexpired keys.
+func (c *DecayingCache[T]) Items() map[string]Item[T] {
+	now := c.now().UnixNano()
+	items := c.c.Items()
+	m := make(map[string]Item[T], len(items))
+	for k, v := range items {
+		m[k] = Item[T]{
+			Object:     v.Object.at(now, c.halfLife),
+			Expiration: v.Expiration,
+			Version:    v.Version,
+		}
+	}
+	return m
+}
+
+// ItemCount returns the number of scores in the cache. This may include scores
+// that have expired, but have not yet been cleaned up.
+func (c *DecayingCache[T]) ItemCount() int {
+	return c.c.ItemCount()
+}
+
+// Flush deletes all scores from the cache.
+func (c *DecayingCache[T]) Flush() {
+	c.c.Flush()
+}
+
+// HalfLife returns the half-life of the scores.
+func (c *DecayingCache[T]) HalfLife() time.Duration {
+	return c.halfLife
+}
+
+// NewDecaying[T constraints.Float](...) returns a new *DecayingCache[T] whose
+// scores decay with the given half-life. If halfLife is less than one, scores
+// do not decay. The default expiration duration and the cleanup interval are
+// handled as in NewAny.
+func NewDecaying[T constraints.Float](halfLife, defaultExpiration, cleanupInterval time.Duration) *DecayingCache[T] {
+	return &DecayingCache[T]{
+		c:        NewAny[decayingScore[T]](defaultExpiration, cleanupInterval),
+		halfLife: halfLife,
+		now:      time.Now,
+	}
+}
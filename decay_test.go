@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestDecaying(halfLife time.Duration) (*DecayingCache[float64], *time.Time) {
+	now := time.Unix(1000, 0)
+	tc := NewDecaying[float64](halfLife, DefaultExpiration, 0)
+	tc.now = func() time.Time { return now }
+	return tc, &now
+}
+
+func TestDecayingCache(t *testing.T) {
+	tc, now := newTestDecaying(time.Minute)
+
+	tc.Set("foo", 100, DefaultExpiration)
+	if x, found := tc.Get("foo"); !found || x != 100 {
+		t.Error("unexpected score:", x, found)
+	}
+
+	*now = now.Add(time.Minute)
+	if x, _ := tc.Get("foo"); x != 50 {
+		t.Error("score did not decay by half:", x)
+	}
+	*now = now.Add(time.Minute)
+	if x, _ := tc.Get("foo"); x != 25 {
+		t.Error("score did not decay by a quarter:", x)
+	}
+
+	if x, err := tc.Increment("foo", 5); err != nil || x != 30 {
+		t.Error("Increment did not add to the decayed score:", x, err)
+	}
+	*now = now.Add(30 * time.Second)
+	if x, _ := tc.Get("foo"); math.Abs(x-30/math.Sqrt2) > 1e-9 {
+		t.Error("unexpected score:", x)
+	}
+
+	if _, err := tc.Increment("bar", 1); !errors.Is(err, ErrNotFound) {
+		t.Error("Increment did not return ErrNotFound:", err)
+	}
+	if x := tc.IncrementOrSet("bar", 8, DefaultExpiration); x != 8 {
+		t.Error("IncrementOrSet did not set bar:", x)
+	}
+	*now = now.Add(time.Minute)
+	if x := tc.IncrementOrSet("bar", 1, DefaultExpiration); x != 5 {
+		t.Error("IncrementOrSet did not add to the decayed score:", x)
+	}
+	if items := tc.Items(); len(items) != 2 || items["bar"].Object != 5 {
+		t.Error("unexpected items:", items)
+	}
+
+	tc.Delete("bar")
+	if _, found := tc.Get("bar"); found {
+		t.Error("bar was not deleted")
+	}
+	tc.Flush()
+	if tc.ItemCount() != 0 {
+		t.Error("Flush did not delete all scores")
+	}
+}
+
+func TestDecayingCacheNoHalfLife(t *testing.T) {
+	tc, now := newTestDecaying(0)
+	tc.Set("foo", 10, DefaultExpiration)
+	*now = now.Add(time.Hour)
+	if x, _ := tc.Get("foo"); x != 10 {
+		t.Error("score decayed without a half-life:", x)
+	}
+}
+
+func TestDecayingCacheExpiration(t *testing.T) {
+	tc := NewDecaying[float32](time.Hour, DefaultExpiration, 0)
+	tc.IncrementOrSet("foo", 1, time.Nanosecond)
+	<-time.After(time.Millisecond)
+	if _, found := tc.Get("foo"); found {
+		t.Error("found an expired score")
+	}
+	if x := tc.IncrementOrSet("foo", 2, DefaultExpiration); x != 2 {
+		t.Error("IncrementOrSet added to an expired score:", x)
+	}
+}
@@ -0,0 +1,147 @@
+package cache
+
+import "time"
+
+// dependencyIndex maps keys to the keys they depend on and to the keys
+// depending on them.
+type dependencyIndex struct {
+	deps       map[string][]string
+	dependents map[string]map[string]struct{}
+}
+
+func newDependencyIndex() *dependencyIndex {
+	return &dependencyIndex{
+		deps:       make(map[string][]string),
+		dependents: make(map[string]map[string]struct{}),
+	}
+}
+
+func (t *dependencyIndex) add(k string, deps []string) {
+	for _, dep := range deps {
+		dependents := t.dependents[dep]
+		if dependents == nil {
+			dependents = make(map[string]struct{})
+			t.dependents[dep] = dependents
+		}
+		if _, found := dependents[k]; found {
+			continue
+		}
+		dependents[k] = struct{}{}
+		t.deps[k] = append(t.deps[k], dep)
+	}
+}
+
+// remove removes the dependencies of k. The keys depending on k are kept.
+func (t *dependencyIndex) remove(k string) {
+	for _, dep := range t.deps[k] {
+		dependents := t.dependents[dep]
+		delete(dependents, k)
+		if len(dependents) == 0 {
+			delete(t.dependents, dep)
+		}
+	}
+	delete(t.deps, k)
+}
+
+// dependsOn reports whether k depends on dep, directly or transitively.
+func (t *dependencyIndex) dependsOn(k, dep string) bool {
+	seen := map[string]struct{}{k: {}}
+	stack := []string{k}
+	for len(stack) > 0 {
+		k := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, d := range t.deps[k] {
+			if d == dep {
+				return true
+			}
+			if _, found := seen[d]; !found {
+				seen[d] = struct{}{}
+				stack = append(stack, d)
+			}
+		}
+	}
+	return false
+}
+
+// SetWithDependencies adds an item to the cache like Set, replacing any
+// existing item and its dependencies, and makes it depend on the given keys:
+// when one of them is deleted, expires or is set or modified, the item is
+// deleted along with the items depending on it, transitively, and the eviction
+// callbacks are called for each of them with the reason EvictionDependency,
+// under which these evictions are also counted. The item expires at the latest
+// with the first of its unexpired dependencies, the keys which are not in the
+// cache are still tracked.
+//
+// Returns ErrDependencyCycle, and leaves the cache untouched, if k is one of
+// the given keys or if one of them already depends on k.
+func (c *anyCache[T]) SetWithDependencies(k string, x T, d time.Duration, deps ...string) error {
+	c.mu.Lock()
+	defer c.unlock()
+
+	for _, dep := range deps {
+		if dep == k || (c.deps != nil && c.deps.dependsOn(dep, k)) {
+			return keyError(k, ErrDependencyCycle)
+		}
+	}
+
+	c.set(k, x, d)
+	if len(deps) == 0 {
+		return nil
+	}
+	if c.deps == nil {
+		c.deps = newDependencyIndex()
+	}
+	c.deps.add(k, deps)
+
+	item := c.items[k]
+	now := time.Now().UnixNano()
+	for _, dep := range deps {
+		// "Inlining" of Expired
+		v, found := c.items[dep]
+		if !found || v.Expiration == 0 || now > v.Expiration {
+			continue
+		}
+		if item.Expiration == 0 || v.Expiration < item.Expiration {
+			item.Expiration = v.Expiration
+		}
+	}
+	c.items[k] = item
+	return nil
+}
+
+// cascade deletes the items depending on k, transitively, and queues their
+// evictions until the cache is unlocked. The cache must be locked.
+func (c *anyCache[T]) cascade(k string) {
+	dependents := c.deps.dependents[k]
+	if len(dependents) == 0 {
+		return
+	}
+	delete(c.deps.dependents, k)
+	for dependent := range dependents {
+		// delete cascades to the dependents of dependent.
+		if v, found := c.delete(dependent); found {
+			c.cascaded = append(c.cascaded, keyAndValue[T]{dependent, v})
+		}
+	}
+}
+
+// unlock unlocks the cache, then records the evictions of the items deleted
+// because one of their dependencies changed and calls the eviction callbacks
+// for them.
+func (c *anyCache[T]) unlock() {
+	cascaded := c.cascaded
+	c.cascaded = nil
+	callbacks := c.evictionCallbacks()
+	c.mu.Unlock()
+
+	if len(cascaded) == 0 {
+		return
+	}
+	c.stats.Load().addEvictions(EvictionDependency, uint64(len(cascaded)))
+	for _, v := range cascaded {
+		c.logEviction(v.key, EvictionDependency)
+	}
+	for _, v := range cascaded {
+		callbacks.call(v.key, v.value, EvictionDependency)
+	}
+}
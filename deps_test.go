@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestDependencies(t *testing.T) {
+	tc := New[string](DefaultExpiration, 0)
+	tc.EnableStats()
+	tc.Set("profile", "alice", DefaultExpiration)
+	if err := tc.SetWithDependencies("header", "<h1>alice</h1>", DefaultExpiration, "profile"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tc.SetWithDependencies("page", "<html>", DefaultExpiration, "header", "profile"); err != nil {
+		t.Fatal(err)
+	}
+	tc.Set("other", "", DefaultExpiration)
+
+	var evicted []string
+	tc.OnEvicted(func(k string, v string) {
+		evicted = append(evicted, k)
+	})
+	reasons := map[string]EvictionReason{}
+	tc.OnEvictedWithReason(func(k string, v string, reason EvictionReason) {
+		reasons[k] = reason
+	})
+
+	// Deleting a dependency deletes its dependents transitively.
+	tc.Delete("profile")
+	sort.Strings(evicted)
+	if len(evicted) != 3 || evicted[0] != "header" || evicted[1] != "page" || evicted[2] != "profile" {
+		t.Error("unexpected evicted items:", evicted)
+	}
+	if tc.ItemCount() != 1 {
+		t.Error("unexpected item count:", tc.ItemCount())
+	}
+	if reasons["profile"] != EvictionDeleted || reasons["header"] != EvictionDependency || reasons["page"] != EvictionDependency {
+		t.Error("unexpected eviction reasons:", reasons)
+	}
+	st := tc.Stats()
+	if st.Evictions[EvictionDependency] != 2 || st.Evictions[EvictionDeleted] != 1 {
+		t.Error("unexpected evictions:", st.Evictions)
+	}
+	if len(tc.deps.deps) != 0 || len(tc.deps.dependents) != 0 {
+		t.Error("dependency index was not cleaned up:", tc.deps.deps, tc.deps.dependents)
+	}
+}
+
+func TestDependenciesReplaced(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+	tc.Set("a", 1, DefaultExpiration)
+	tc.Set("b", 2, DefaultExpiration)
+	tc.SetWithDependencies("sum", 3, DefaultExpiration, "a", "b")
+
+	// Setting or modifying a dependency deletes its dependents.
+	tc.Set("a", 10, DefaultExpiration)
+	if _, found := tc.Get("sum"); found {
+		t.Error("sum was not deleted when a was set")
+	}
+	tc.SetWithDependencies("sum", 12, DefaultExpiration, "a", "b")
+	tc.Increment("b", 1)
+	if _, found := tc.Get("sum"); found {
+		t.Error("sum was not deleted when b was incremented")
+	}
+
+	// Setting a dependent again replaces its dependencies.
+	tc.SetWithDependencies("sum", 13, DefaultExpiration, "a", "b")
+	tc.Set("sum", 13, DefaultExpiration)
+	tc.Delete("a")
+	if _, found := tc.Get("sum"); !found {
+		t.Error("sum was deleted after its dependencies were replaced")
+	}
+
+	// Dependencies which are not in the cache are still tracked.
+	tc.SetWithDependencies("c", 0, DefaultExpiration, "a")
+	tc.Set("a", 1, DefaultExpiration)
+	if _, found := tc.Get("c"); found {
+		t.Error("c was not deleted when a was set")
+	}
+}
+
+func TestDependenciesExpired(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.EnableStats()
+	tc.Set("a", 1, 50*time.Millisecond)
+	tc.SetWithDependencies("b", 2, NoExpiration, "a")
+	tc.SetWithDependencies("c", 3, NoExpiration, "b")
+
+	// Dependents expire at the latest with their dependencies.
+	items := tc.Items()
+	if items["b"].Expiration != items["a"].Expiration || items["c"].Expiration != items["a"].Expiration {
+		t.Error("dependents expire after their dependencies:", items)
+	}
+
+	var evicted []string
+	tc.OnEvicted(func(k string, v int) {
+		evicted = append(evicted, k)
+	})
+	<-time.After(100 * time.Millisecond)
+	if _, found := tc.Get("c"); found {
+		t.Error("c did not expire with a")
+	}
+	tc.DeleteExpired()
+	if len(evicted) != 3 || tc.ItemCount() != 0 {
+		t.Error("unexpected evicted items:", evicted)
+	}
+	st := tc.Stats()
+	if st.Evictions[EvictionExpired]+st.Evictions[EvictionDependency] != 3 {
+		t.Error("unexpected evictions:", st.Evictions)
+	}
+}
+
+func TestDependencyCycle(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetWithDependencies("b", 2, DefaultExpiration, "a")
+	tc.SetWithDependencies("c", 3, DefaultExpiration, "b")
+
+	for _, dep := range []string{"a", "c"} {
+		err := tc.SetWithDependencies("a", 1, DefaultExpiration, dep)
+		if !errors.Is(err, ErrDependencyCycle) {
+			t.Errorf("SetWithDependencies(%q) returned %v", dep, err)
+		}
+	}
+	if _, found := tc.Get("a"); found {
+		t.Error("a was set despite the cycle")
+	}
+	if tc.ItemCount() != 2 {
+		t.Error("dependents were deleted despite the cycle:", tc.ItemCount())
+	}
+
+	tc.Flush()
+	if err := tc.SetWithDependencies("a", 1, DefaultExpiration, "c"); err != nil {
+		t.Error("dependencies were not flushed:", err)
+	}
+}
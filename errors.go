@@ -0,0 +1,43 @@
+package cache
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when an item does not exist or has expired.
+	ErrNotFound = errors.New("item not found")
+	// ErrAlreadyExists is returned by Add when an unexpired item already exists.
+	ErrAlreadyExists = errors.New("item already exists")
+	// ErrOverflow is returned when a numeric operation overflows the type of
+	// the item.
+	ErrOverflow = errors.New("numeric overflow")
+	// ErrDivisionByZero is returned when dividing an item by zero.
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrClosed is returned when writing to a closed cache.
+	ErrClosed = errors.New("cache closed")
+	// ErrVersionMismatch is returned by SetIfVersion when the item was modified
+	// since its version was read.
+	ErrVersionMismatch = errors.New("item version mismatch")
+	// ErrDependencyCycle is returned by SetWithDependencies when an item would
+	// depend on itself.
+	ErrDependencyCycle = errors.New("dependency cycle")
+)
+
+// KeyError records an error and the key of the item it occurred on. The errors
+// returned by the caches are KeyErrors wrapping one of the sentinel errors, so
+// they can be tested with errors.Is and the key retrieved with errors.As.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return e.Err.Error() + ": " + e.Key
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+func keyError(k string, err error) error {
+	return &KeyError{Key: k, Err: err}
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestKeyError(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+	tc.Set("foo", 1, DefaultExpiration)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+		key  string
+	}{
+		{"Add", tc.Add("foo", 2, DefaultExpiration), ErrAlreadyExists, "foo"},
+		{"Replace", tc.Replace("bar", 2, DefaultExpiration), ErrNotFound, "bar"},
+		{"Increment", func() error { _, err := tc.Increment("bar", 1); return err }(), ErrNotFound, "bar"},
+		{"Decrement", func() error { _, err := tc.Decrement("bar", 1); return err }(), ErrNotFound, "bar"},
+		{"SetIfVersion", tc.SetIfVersion("foo", 2, DefaultExpiration, 0), ErrVersionMismatch, "foo"},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: error %v is not %v", tt.name, tt.err, tt.want)
+			continue
+		}
+		var ke *KeyError
+		if !errors.As(tt.err, &ke) || ke.Key != tt.key {
+			t.Errorf("%s: error %v does not carry key %s", tt.name, tt.err, tt.key)
+		}
+	}
+
+	if msg := keyError("foo", ErrAlreadyExists).Error(); msg != "item already exists: foo" {
+		t.Error("unexpected error message:", msg)
+	}
+}
+
+func TestNoopKeyError(t *testing.T) {
+	tc := NewNoopNumeric[int](DefaultExpiration, 0)
+	if err := tc.Add("foo", 1, DefaultExpiration); err != nil {
+		t.Error("Add returned an error:", err)
+	}
+	if err := tc.Replace("foo", 1, time.Minute); !errors.Is(err, ErrNotFound) {
+		t.Error("Replace did not return ErrNotFound:", err)
+	}
+	if _, err := tc.Increment("foo", 1); !errors.Is(err, ErrNotFound) {
+		t.Error("Increment did not return ErrNotFound:", err)
+	}
+}
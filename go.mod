@@ -1,6 +1,6 @@
 module sylr.dev/cache/v3
 
-go 1.18
+go 1.23.0
 
 require (
 	go.uber.org/goleak v1.1.12
@@ -0,0 +1,146 @@
+package inspect
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// DefaultLimit is the number of keys listed per page when no limit is given.
+const DefaultLimit = 100
+
+// MaxLimit is the maximum number of keys listed per page.
+const MaxLimit = 1000
+
+// HandlerOptions configures the handler returned by Handler.
+type HandlerOptions struct {
+	// AllowWrite enables the endpoints deleting keys and flushing caches.
+	AllowWrite bool
+	// Authorize is an (optional) function called before serving the endpoints
+	// deleting keys and flushing caches. The request is rejected with 403 if it
+	// returns false.
+	Authorize func(r *http.Request) bool
+}
+
+// Handler returns an http.Handler serving the registered caches as JSON:
+//
+//	GET    /                      lists the caches with their item counts and stats
+//	GET    /{cache}               describes a cache and its TTL distribution
+//	GET    /{cache}/keys          lists the keys of a cache, see below
+//	DELETE /{cache}/keys/{key...} deletes a key (if writes are allowed)
+//	POST   /{cache}/flush         flushes a cache (if writes are allowed)
+//
+// The key listing accepts the prefix, offset and limit query parameters. The
+// limit must be between 1 and MaxLimit.
+//
+// The handler expects to be mounted at the root, use http.StripPrefix to mount
+// it elsewhere.
+func (r *Registry) Handler(opts HandlerOptions) http.Handler {
+	h := &handler{r: r, opts: opts}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", h.list)
+	mux.HandleFunc("GET /{cache}", h.info)
+	mux.HandleFunc("GET /{cache}/keys", h.keys)
+	mux.HandleFunc("DELETE /{cache}/keys/{key...}", h.guard(h.delete))
+	mux.HandleFunc("POST /{cache}/flush", h.guard(h.flush))
+	return mux
+}
+
+type handler struct {
+	r    *Registry
+	opts HandlerOptions
+}
+
+func (h *handler) guard(f http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if !h.opts.AllowWrite {
+			http.Error(w, "writes are not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if h.opts.Authorize != nil && !h.opts.Authorize(req) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		f(w, req)
+	}
+}
+
+func (h *handler) list(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, h.r.Infos())
+}
+
+func (h *handler) info(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("cache")
+	info, found := h.r.Info(name)
+	if !found {
+		http.NotFound(w, req)
+		return
+	}
+	ttl, _ := h.r.TTLDistribution(name)
+	writeJSON(w, struct {
+		Info
+		TTL []TTLBucket `json:"ttl"`
+	}{info, ttl})
+}
+
+func (h *handler) keys(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	offset, err := intParam(q.Get("offset"), 0)
+	if err != nil {
+		http.Error(w, "invalid offset", http.StatusBadRequest)
+		return
+	}
+	limit, err := intParam(q.Get("limit"), DefaultLimit)
+	if err != nil || limit < 1 || limit > MaxLimit {
+		http.Error(w, "invalid limit", http.StatusBadRequest)
+		return
+	}
+
+	entries, total, found := h.r.Keys(req.PathValue("cache"), q.Get("prefix"), offset, limit)
+	if !found {
+		http.NotFound(w, req)
+		return
+	}
+	if entries == nil {
+		entries = []Entry{}
+	}
+	writeJSON(w, struct {
+		Total  int     `json:"total"`
+		Offset int     `json:"offset"`
+		Keys   []Entry `json:"keys"`
+	}{total, offset, entries})
+}
+
+func (h *handler) delete(w http.ResponseWriter, req *http.Request) {
+	if !h.r.Delete(req.PathValue("cache"), req.PathValue("key")) {
+		http.NotFound(w, req)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *handler) flush(w http.ResponseWriter, req *http.Request) {
+	if !h.r.Flush(req.PathValue("cache")) {
+		http.NotFound(w, req)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func intParam(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
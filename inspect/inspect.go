@@ -0,0 +1,360 @@
+// Package inspect exposes sylr.dev/cache/v3 caches over expvar and HTTP for
+// debugging purposes. It only depends on the standard library.
+package inspect
+
+import (
+	"container/heap"
+	"expvar"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sylr.dev/cache/v3"
+)
+
+// Cache is implemented by the caches a Registry can expose, e.g.
+// *cache.AnyCache[T] and *cache.NumericCache[T].
+type Cache[T any] interface {
+	Items() map[string]cache.Item[T]
+	ItemCount() int
+	Delete(k string)
+	Flush()
+}
+
+type statser interface {
+	Stats() cache.Stats
+}
+
+// walker is implemented by the caches whose keys can be listed without copying
+// all their items, e.g. *cache.AnyCache[T].
+type walker[T any] interface {
+	RangeChunked(size int, f func(k string, v T) bool)
+	GetItem(k string) (cache.ItemInfo[T], bool)
+}
+
+// statsEnabler is implemented by the caches whose statistics are disabled by
+// default, e.g. *cache.AnyCache[T].
+type statsEnabler interface {
+	EnableStats()
+}
+
+// inspectable is the type erased view of a registered cache.
+type inspectable interface {
+	itemCount() int
+	stats() (cache.Stats, bool)
+	entries() []Entry
+	page(prefix string, offset, limit int) ([]Entry, int)
+	delete(k string)
+	flush()
+}
+
+type adapter[T any] struct {
+	c Cache[T]
+}
+
+func (a adapter[T]) itemCount() int {
+	return a.c.ItemCount()
+}
+
+func (a adapter[T]) stats() (cache.Stats, bool) {
+	if s, ok := a.c.(statser); ok {
+		return s.Stats(), true
+	}
+	return cache.Stats{}, false
+}
+
+func (a adapter[T]) entries() []Entry {
+	items := a.c.Items()
+	entries := make([]Entry, 0, len(items))
+	for k, item := range items {
+		e := Entry{Key: k}
+		if item.Expiration > 0 {
+			t := time.Unix(0, item.Expiration)
+			e.Expiration = &t
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// page returns the entries of the page of keys starting with prefix and the
+// total number of such keys. If the cache implements walker, the keys are
+// walked in chunks and only the offset+limit lowest ones are kept, otherwise
+// all the items of the cache are copied.
+func (a adapter[T]) page(prefix string, offset, limit int) ([]Entry, int) {
+	w, ok := a.c.(walker[T])
+	if !ok {
+		var entries []Entry
+		for _, e := range a.entries() {
+			if strings.HasPrefix(e.Key, prefix) {
+				entries = append(entries, e)
+			}
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Key < entries[j].Key
+		})
+		total := len(entries)
+		return entries[min(offset, total):min(offset+limit, total)], total
+	}
+
+	h := &keyHeap{}
+	total := 0
+	w.RangeChunked(0, func(k string, _ T) bool {
+		if !strings.HasPrefix(k, prefix) {
+			return true
+		}
+		total++
+		if h.Len() < offset+limit {
+			heap.Push(h, k)
+		} else if k < (*h)[0] {
+			(*h)[0] = k
+			heap.Fix(h, 0)
+		}
+		return true
+	})
+
+	keys := make([]string, h.Len())
+	for i := len(keys) - 1; i >= 0; i-- {
+		keys[i] = heap.Pop(h).(string)
+	}
+	var entries []Entry
+	for _, k := range keys[min(offset, len(keys)):] {
+		item, found := w.GetItem(k)
+		if !found {
+			continue
+		}
+		e := Entry{Key: k}
+		if item.Expiration > 0 {
+			t := time.Unix(0, item.Expiration)
+			e.Expiration = &t
+		}
+		entries = append(entries, e)
+	}
+	return entries, total
+}
+
+// keyHeap is a max-heap of keys.
+type keyHeap []string
+
+func (h keyHeap) Len() int           { return len(h) }
+func (h keyHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h keyHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *keyHeap) Push(x any)        { *h = append(*h, x.(string)) }
+func (h *keyHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+func (a adapter[T]) delete(k string) {
+	a.c.Delete(k)
+}
+
+func (a adapter[T]) flush() {
+	a.c.Flush()
+}
+
+// Entry describes an item of a cache.
+type Entry struct {
+	Key string `json:"key"`
+	// Expiration is nil if the item never expires.
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+// Info describes a cache.
+type Info struct {
+	Name  string       `json:"name"`
+	Items int          `json:"items"`
+	Stats *cache.Stats `json:"stats,omitempty"`
+}
+
+// TTLBucket counts the items of a cache whose remaining time to live is at
+// most Max (and more than the Max of the previous bucket).
+type TTLBucket struct {
+	// Max is the upper bound of the bucket. It is 0 for the bucket of items
+	// which never expire.
+	Max   time.Duration `json:"max"`
+	Label string        `json:"label"`
+	Count int           `json:"count"`
+}
+
+// TTLBuckets are the upper bounds of the buckets of the TTL distribution.
+var TTLBuckets = []time.Duration{
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+}
+
+// Registry holds named caches.
+type Registry struct {
+	mu     sync.RWMutex
+	caches map[string]inspectable
+}
+
+// NewRegistry returns a new empty *Registry.
+func NewRegistry() *Registry {
+	return &Registry{caches: make(map[string]inspectable)}
+}
+
+// DefaultRegistry is the registry used by the package level functions.
+var DefaultRegistry = NewRegistry()
+
+// Register adds c to r under name and enables its statistics if it has an
+// EnableStats method. It returns an error if a cache is already registered
+// under that name.
+func Register[T any](r *Registry, name string, c Cache[T]) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, found := r.caches[name]; found {
+		return fmt.Errorf("cache %s already registered", name)
+	}
+	if s, ok := c.(statsEnabler); ok {
+		s.EnableStats()
+	}
+	r.caches[name] = adapter[T]{c}
+	return nil
+}
+
+// Unregister removes the cache registered under name from r.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	delete(r.caches, name)
+	r.mu.Unlock()
+}
+
+func (r *Registry) get(name string) (inspectable, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, found := r.caches[name]
+	return c, found
+}
+
+func (r *Registry) names() []string {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.caches))
+	for name := range r.caches {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Infos returns the description of all the registered caches sorted by name.
+func (r *Registry) Infos() []Info {
+	names := r.names()
+	infos := make([]Info, 0, len(names))
+	for _, name := range names {
+		if info, found := r.Info(name); found {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// Info returns the description of the cache registered under name.
+func (r *Registry) Info(name string) (Info, bool) {
+	c, found := r.get(name)
+	if !found {
+		return Info{}, false
+	}
+	info := Info{Name: name, Items: c.itemCount()}
+	if st, ok := c.stats(); ok {
+		info.Stats = &st
+	}
+	return info, true
+}
+
+// TTLDistribution returns the number of unexpired items of the cache registered
+// under name per remaining time to live bucket (see TTLBuckets). The last two
+// buckets count the items expiring after the last bucket and the items which
+// never expire.
+func (r *Registry) TTLDistribution(name string) ([]TTLBucket, bool) {
+	c, found := r.get(name)
+	if !found {
+		return nil, false
+	}
+
+	buckets := make([]TTLBucket, len(TTLBuckets)+2)
+	for i, max := range TTLBuckets {
+		buckets[i] = TTLBucket{Max: max, Label: "<=" + max.String()}
+	}
+	buckets[len(TTLBuckets)] = TTLBucket{Max: -1, Label: ">" + TTLBuckets[len(TTLBuckets)-1].String()}
+	buckets[len(TTLBuckets)+1] = TTLBucket{Label: "never"}
+
+	now := time.Now()
+	for _, e := range c.entries() {
+		if e.Expiration == nil {
+			buckets[len(TTLBuckets)+1].Count++
+			continue
+		}
+		ttl := e.Expiration.Sub(now)
+		i := 0
+		for i < len(TTLBuckets) && ttl > TTLBuckets[i] {
+			i++
+		}
+		buckets[i].Count++
+	}
+	return buckets, true
+}
+
+// Keys returns at most limit entries of the cache registered under name whose
+// keys start with prefix, sorted by key and starting at offset, along with the
+// total number of matching entries. A limit lower than 1 is replaced by
+// DefaultLimit and a limit greater than MaxLimit by MaxLimit.
+func (r *Registry) Keys(name, prefix string, offset, limit int) ([]Entry, int, bool) {
+	c, found := r.get(name)
+	if !found {
+		return nil, 0, false
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if limit < 1 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	entries, total := c.page(prefix, offset, limit)
+	return entries, total, true
+}
+
+// Delete deletes k from the cache registered under name.
+func (r *Registry) Delete(name, k string) bool {
+	c, found := r.get(name)
+	if found {
+		c.delete(k)
+	}
+	return found
+}
+
+// Flush deletes all the items of the cache registered under name.
+func (r *Registry) Flush(name string) bool {
+	c, found := r.get(name)
+	if found {
+		c.flush()
+	}
+	return found
+}
+
+// Var returns an expvar.Var describing all the registered caches.
+func (r *Registry) Var() expvar.Var {
+	return expvar.Func(func() any {
+		return r.Infos()
+	})
+}
+
+// Publish publishes the description of all the registered caches as an expvar
+// variable under name. Like expvar.Publish, it panics if name is already
+// published.
+func (r *Registry) Publish(name string) {
+	expvar.Publish(name, r.Var())
+}
@@ -0,0 +1,214 @@
+package inspect
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"sylr.dev/cache/v3"
+)
+
+func newTestRegistry(t *testing.T) (*Registry, *cache.AnyCache[string]) {
+	r := NewRegistry()
+	c := cache.New[string](cache.DefaultExpiration, 0)
+	c.EnableStats()
+	c.Set("user:1", "a", cache.NoExpiration)
+	c.Set("user:2", "b", 30*time.Second)
+	c.Set("user:3", "c", 2*time.Hour)
+	c.Set("group:1", "d", 48*time.Hour)
+	if err := Register[string](r, "users", c); err != nil {
+		t.Fatal(err)
+	}
+	if err := Register[string](r, "users", c); err == nil {
+		t.Error("registering a cache twice did not return an error")
+	}
+	n := cache.NewNumeric[int](cache.DefaultExpiration, 0)
+	if err := Register[int](r, "counters", n); err != nil {
+		t.Fatal(err)
+	}
+	return r, c
+}
+
+// published counts the registries published by the tests, whose expvar names
+// must be unique within the process, e.g. when run with -count.
+var published int32
+
+// itemsCache only implements Cache, so that its keys are listed by copying its
+// items.
+type itemsCache[T any] struct {
+	Cache[T]
+}
+
+func TestRegistry(t *testing.T) {
+	r, _ := newTestRegistry(t)
+
+	infos := r.Infos()
+	if len(infos) != 2 || infos[0].Name != "counters" || infos[1].Name != "users" {
+		t.Fatal("unexpected infos:", infos)
+	}
+	if infos[1].Items != 4 || infos[1].Stats == nil || infos[1].Stats.Sets != 4 {
+		t.Error("unexpected users info:", infos[1])
+	}
+
+	ttl, _ := r.TTLDistribution("users")
+	counts := make(map[string]int)
+	for _, b := range ttl {
+		counts[b.Label] = b.Count
+	}
+	if counts["<=1m0s"] != 1 || counts["<=6h0m0s"] != 1 || counts[">24h0m0s"] != 1 || counts["never"] != 1 {
+		t.Error("unexpected TTL distribution:", ttl)
+	}
+
+	entries, total, _ := r.Keys("users", "user:", 1, 1)
+	if total != 3 || len(entries) != 1 || entries[0].Key != "user:2" {
+		t.Error("unexpected keys:", entries, total)
+	}
+	entries, total, _ = r.Keys("users", "", 10, 0)
+	if total != 4 || len(entries) != 0 {
+		t.Error("unexpected keys:", entries, total)
+	}
+	if _, _, found := r.Keys("nope", "", 0, 0); found {
+		t.Error("keys of unknown cache were found")
+	}
+
+	v := r.Var().String()
+	if !strings.Contains(v, `"name":"users"`) {
+		t.Error("unexpected expvar:", v)
+	}
+	name := fmt.Sprintf("inspect_test_caches_%d", atomic.AddInt32(&published, 1))
+	r.Publish(name)
+	if expvar.Get(name) == nil {
+		t.Error("registry was not published")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	r, c := newTestRegistry(t)
+	srv := httptest.NewServer(r.Handler(HandlerOptions{
+		AllowWrite: true,
+		Authorize: func(req *http.Request) bool {
+			return req.Header.Get("X-Token") == "secret"
+		},
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/users/keys?prefix=user:&limit=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var page struct {
+		Total int
+		Keys  []Entry
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if page.Total != 3 || len(page.Keys) != 2 || page.Keys[0].Key != "user:1" || page.Keys[0].Expiration != nil {
+		t.Error("unexpected keys page:", page)
+	}
+
+	resp, _ = http.Get(srv.URL + "/users")
+	var info struct {
+		Info
+		TTL []TTLBucket
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if info.Items != 4 || len(info.TTL) != len(TTLBuckets)+2 {
+		t.Error("unexpected info:", info)
+	}
+
+	resp, _ = http.Get(srv.URL + "/nope")
+	if resp.StatusCode != http.StatusNotFound {
+		t.Error("unknown cache status is not 404:", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/users/keys/user:1", nil)
+	resp, _ = http.DefaultClient.Do(req)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Error("unauthorized delete status is not 403:", resp.StatusCode)
+	}
+	req.Header.Set("X-Token", "secret")
+	resp, _ = http.DefaultClient.Do(req)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Error("delete status is not 204:", resp.StatusCode)
+	}
+	if _, found := c.Get("user:1"); found {
+		t.Error("user:1 was not deleted")
+	}
+
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/users/flush", nil)
+	req.Header.Set("X-Token", "secret")
+	resp, _ = http.DefaultClient.Do(req)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Error("flush status is not 204:", resp.StatusCode)
+	}
+	if n := c.ItemCount(); n != 0 {
+		t.Error("users was not flushed:", n)
+	}
+}
+
+func TestHandlerReadOnly(t *testing.T) {
+	r, c := newTestRegistry(t)
+	srv := httptest.NewServer(r.Handler(HandlerOptions{}))
+	defer srv.Close()
+
+	resp, _ := http.Post(srv.URL+"/users/flush", "", nil)
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Error("read only flush status is not 405:", resp.StatusCode)
+	}
+	if n := c.ItemCount(); n != 4 {
+		t.Error("users was flushed:", n)
+	}
+}
+
+func TestRegistryKeys(t *testing.T) {
+	r := NewRegistry()
+	c := cache.New[int](cache.DefaultExpiration, 0)
+	for i := 0; i < MaxLimit+10; i++ {
+		c.Set(fmt.Sprintf("k%04d", i), i, cache.DefaultExpiration)
+	}
+	c.Set("expired", -1, time.Nanosecond)
+	<-time.After(time.Millisecond)
+	Register[int](r, "walked", c)
+	Register[int](r, "copied", itemsCache[int]{c})
+
+	for _, name := range []string{"walked", "copied"} {
+		entries, total, _ := r.Keys(name, "k", 5, 3)
+		if total != MaxLimit+10 || len(entries) != 3 || entries[0].Key != "k0005" || entries[2].Key != "k0007" {
+			t.Errorf("unexpected %s keys: %v, %d", name, entries, total)
+		}
+		if entries, _, _ := r.Keys(name, "", 0, 0); len(entries) != DefaultLimit {
+			t.Errorf("%s limit 0 was not replaced by DefaultLimit: %d", name, len(entries))
+		}
+		if entries, _, _ := r.Keys(name, "", 0, MaxLimit+1); len(entries) != MaxLimit {
+			t.Errorf("%s limit was not clamped to MaxLimit: %d", name, len(entries))
+		}
+		if entries, _, _ := r.Keys(name, "", MaxLimit+9, 10); len(entries) != 1 || entries[0].Key != "k"+strconv.Itoa(MaxLimit+9) {
+			t.Errorf("unexpected %s last page: %v", name, entries)
+		}
+	}
+
+	srv := httptest.NewServer(r.Handler(HandlerOptions{}))
+	defer srv.Close()
+	for _, limit := range []string{"0", strconv.Itoa(MaxLimit + 1)} {
+		resp, err := http.Get(srv.URL + "/walked/keys?limit=" + limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("limit %s status is not 400: %d", limit, resp.StatusCode)
+		}
+	}
+}
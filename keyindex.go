@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+)
+
+// keyIndex is a radix tree of the keys of a cache, used to find the keys
+// starting with a prefix without scanning the whole cache.
+type keyIndex struct {
+	root radixNode
+}
+
+// radixNode is a node of a keyIndex. Its prefix is the label of the edge from
+// its parent, leaf tells whether a key ends at the node and its children are
+// sorted by the first byte of their prefix, which are all different.
+type radixNode struct {
+	prefix   string
+	leaf     bool
+	children []*radixNode
+}
+
+func newKeyIndex() *keyIndex {
+	return &keyIndex{}
+}
+
+// child returns the position of the child of n whose prefix starts with b, and
+// the child if there is one.
+func (n *radixNode) child(b byte) (int, *radixNode) {
+	i := sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].prefix[0] >= b
+	})
+	if i < len(n.children) && n.children[i].prefix[0] == b {
+		return i, n.children[i]
+	}
+	return i, nil
+}
+
+func commonPrefixLen(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func (t *keyIndex) insert(k string) {
+	n := &t.root
+	for {
+		if k == "" {
+			n.leaf = true
+			return
+		}
+
+		i, child := n.child(k[0])
+		if child == nil {
+			n.children = append(n.children, nil)
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = &radixNode{prefix: k, leaf: true}
+			return
+		}
+
+		l := commonPrefixLen(k, child.prefix)
+		if l < len(child.prefix) {
+			// Split the edge to the child.
+			split := &radixNode{prefix: child.prefix[:l], children: []*radixNode{child}}
+			child.prefix = child.prefix[l:]
+			n.children[i] = split
+			child = split
+		}
+		n = child
+		k = k[l:]
+	}
+}
+
+func (t *keyIndex) delete(k string) {
+	t.root.remove(k)
+}
+
+// remove removes the key k, relative to n, and compacts the nodes on its path.
+// It returns whether the key was found.
+func (n *radixNode) remove(k string) bool {
+	if k == "" {
+		if !n.leaf {
+			return false
+		}
+		n.leaf = false
+		return true
+	}
+
+	i, child := n.child(k[0])
+	if child == nil || !strings.HasPrefix(k, child.prefix) {
+		return false
+	}
+	if !child.remove(k[len(child.prefix):]) {
+		return false
+	}
+
+	if !child.leaf {
+		switch len(child.children) {
+		case 0:
+			n.children = append(n.children[:i], n.children[i+1:]...)
+		case 1:
+			// Merge the child with its only child.
+			grandchild := child.children[0]
+			grandchild.prefix = child.prefix + grandchild.prefix
+			n.children[i] = grandchild
+		}
+	}
+	return true
+}
+
+// walkPrefix calls f, in lexicographic order, with the keys starting with
+// prefix until it returns false.
+func (t *keyIndex) walkPrefix(prefix string, f func(k string) bool) {
+	n := &t.root
+	path := ""
+	for prefix != "" {
+		_, child := n.child(prefix[0])
+		if child == nil {
+			return
+		}
+		switch {
+		case strings.HasPrefix(prefix, child.prefix):
+			prefix = prefix[len(child.prefix):]
+		case strings.HasPrefix(child.prefix, prefix):
+			prefix = ""
+		default:
+			return
+		}
+		path += child.prefix
+		n = child
+	}
+	n.walk(path, f)
+}
+
+func (n *radixNode) walk(path string, f func(k string) bool) bool {
+	if n.leaf && !f(path) {
+		return false
+	}
+	for _, child := range n.children {
+		if !child.walk(path+child.prefix, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkAfter calls f, in lexicographic order, with the keys greater than after
+// until it returns false.
+func (t *keyIndex) walkAfter(after string, f func(k string) bool) {
+	t.root.walkAfter("", after, f)
+}
+
+// walkAfter is like walk but skips the keys lower than or equal to after.
+func (n *radixNode) walkAfter(path, after string, f func(k string) bool) bool {
+	if !strings.HasPrefix(after, path) {
+		// The keys of the subtree are either all greater than after or all
+		// lower.
+		if path > after {
+			return n.walk(path, f)
+		}
+		return true
+	}
+	// The key ending at n, if any, is a prefix of after, so it is not greater.
+	for _, child := range n.children {
+		if !child.walkAfter(path+child.prefix, after, f) {
+			return false
+		}
+	}
+	return true
+}
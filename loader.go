@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultBatchWindow is the time GetManyOrLoad waits for concurrent callers to
+// add their missing keys to a batch before calling the batch loader.
+const DefaultBatchWindow = time.Millisecond
+
+// DefaultBatchTimeout is the time the batch loader is given to load a batch.
+const DefaultBatchTimeout = 10 * time.Second
+
+// BatchLoaderFunc loads the values of the given keys. Keys which cannot be
+// found are absent from the returned map.
+type BatchLoaderFunc[T any] func(ctx context.Context, ks []string) (map[string]T, error)
+
+// batchLoader coalesces the cache misses of concurrent GetManyOrLoad callers
+// into batches.
+type batchLoader[T any] struct {
+	mu      sync.Mutex
+	window  time.Duration
+	timeout time.Duration
+	pending *loadBatch[T]
+}
+
+type loadBatch[T any] struct {
+	keys  map[string]struct{}
+	done  chan struct{}
+	items map[string]T
+	err   error
+}
+
+// SetBatchWindow sets the time GetManyOrLoad waits for concurrent callers to
+// add their missing keys to a batch before calling the batch loader. If d is 0,
+// DefaultBatchWindow is used. If d is negative, batches are not coalesced.
+func (c *anyCache[T]) SetBatchWindow(d time.Duration) {
+	c.loader.mu.Lock()
+	c.loader.window = d
+	c.loader.mu.Unlock()
+}
+
+// SetBatchTimeout sets the time the batch loader is given to load a batch. If d
+// is 0, DefaultBatchTimeout is used. If d is negative, loads have no timeout.
+func (c *anyCache[T]) SetBatchTimeout(d time.Duration) {
+	c.loader.mu.Lock()
+	c.loader.timeout = d
+	c.loader.mu.Unlock()
+}
+
+// GetManyOrLoad gets the given keys from the cache under a single read lock and
+// loads the missing ones using load. Missing keys of concurrent callers issued
+// within the batch window are coalesced into a single call to the loader of the
+// caller which opened the batch; concurrent callers are therefore expected to
+// pass equivalent loaders. Loaded items are cached with the default expiration.
+//
+// A batch is loaded with a context which is not canceled along with the context
+// of any of its callers, but carries the values of the context of the caller
+// which opened it (e.g. its trace) and times out after the batch timeout (see
+// SetBatchTimeout). A caller whose context is done returns early with its
+// error, without canceling the batch.
+//
+// Keys which could neither be found in the cache nor loaded are absent from the
+// returned map. Keys cached as missing (see SetMissing) are not loaded, and keys
+// the loader does not return are cached as missing if a missing expiration has
+// been set with SetMissingExpiration. If the loader fails, the cached items are
+// returned along with the loader's error.
+func (c *anyCache[T]) GetManyOrLoad(ctx context.Context, ks []string, load BatchLoaderFunc[T]) (map[string]T, error) {
+	m, missing := c.getMany(ks)
+	if len(missing) == 0 {
+		return m, nil
+	}
+
+	b := c.enqueueLoad(ctx, missing, load)
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return m, ctx.Err()
+	}
+
+	if b.err != nil {
+		return m, b.err
+	}
+	for _, k := range missing {
+		if x, found := b.items[k]; found {
+			m[k] = x
+		}
+	}
+	return m, nil
+}
+
+// enqueueLoad adds ks to the pending batch, opening a new one if there is none,
+// and returns the batch the keys will be loaded with.
+func (c *anyCache[T]) enqueueLoad(ctx context.Context, ks []string, load BatchLoaderFunc[T]) *loadBatch[T] {
+	l := &c.loader
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if b := l.pending; b != nil {
+		for _, k := range ks {
+			b.keys[k] = struct{}{}
+		}
+		return b
+	}
+
+	b := &loadBatch[T]{
+		keys: make(map[string]struct{}, len(ks)),
+		done: make(chan struct{}),
+	}
+	for _, k := range ks {
+		b.keys[k] = struct{}{}
+	}
+
+	timeout := l.timeout
+	if timeout == 0 {
+		timeout = DefaultBatchTimeout
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	window := l.window
+	if window == 0 {
+		window = DefaultBatchWindow
+	}
+	if window < 0 {
+		go c.runLoad(ctx, timeout, b, load)
+		return b
+	}
+
+	l.pending = b
+	time.AfterFunc(window, func() {
+		c.runLoad(ctx, timeout, b, load)
+	})
+	return b
+}
+
+func (c *anyCache[T]) runLoad(ctx context.Context, timeout time.Duration, b *loadBatch[T], load BatchLoaderFunc[T]) {
+	l := &c.loader
+	l.mu.Lock()
+	if l.pending == b {
+		l.pending = nil
+	}
+	ks := make([]string, 0, len(b.keys))
+	for k := range b.keys {
+		ks = append(ks, k)
+	}
+	l.mu.Unlock()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	b.items, b.err = load(ctx, ks)
+	c.recordLoad(time.Since(start), b.err)
+	if b.err == nil {
+		c.mu.Lock()
+		for k, x := range b.items {
+			c.set(k, x, DefaultExpiration)
+		}
+		c.setLoadedMissing(ks, b.items)
+		c.unlock()
+	}
+	close(b.done)
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetMany(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.Set("a", 1, DefaultExpiration)
+	tc.Set("b", 2, DefaultExpiration)
+	tc.Set("c", 3, time.Nanosecond)
+	<-time.After(time.Millisecond)
+
+	m := tc.GetMany([]string{"a", "b", "c", "d"})
+	if len(m) != 2 || m["a"] != 1 || m["b"] != 2 {
+		t.Error("GetMany returned unexpected items:", m)
+	}
+}
+
+func TestGetManyOrLoad(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.Set("a", 1, DefaultExpiration)
+
+	var loaded []string
+	m, err := tc.GetManyOrLoad(context.Background(), []string{"a", "b", "c"}, func(ctx context.Context, ks []string) (map[string]int, error) {
+		loaded = append(loaded, ks...)
+		return map[string]int{"b": 2}, nil
+	})
+	if err != nil {
+		t.Error("GetManyOrLoad returned an error:", err)
+	}
+	if len(m) != 2 || m["a"] != 1 || m["b"] != 2 {
+		t.Error("GetManyOrLoad returned unexpected items:", m)
+	}
+	sort.Strings(loaded)
+	if len(loaded) != 2 || loaded[0] != "b" || loaded[1] != "c" {
+		t.Error("loader was called with unexpected keys:", loaded)
+	}
+	if x, found := tc.Get("b"); !found || x != 2 {
+		t.Error("b was not cached after being loaded:", x)
+	}
+
+	_, err = tc.GetManyOrLoad(context.Background(), []string{"c"}, func(ctx context.Context, ks []string) (map[string]int, error) {
+		return nil, errors.New("load failed")
+	})
+	if err == nil {
+		t.Error("GetManyOrLoad did not return the loader error")
+	}
+}
+
+func TestGetManyOrLoadCoalesce(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetBatchWindow(50 * time.Millisecond)
+
+	var calls int32
+	load := func(ctx context.Context, ks []string) (map[string]int, error) {
+		atomic.AddInt32(&calls, 1)
+		m := make(map[string]int, len(ks))
+		for _, k := range ks {
+			m[k], _ = strconv.Atoi(k)
+		}
+		return m, nil
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			k := strconv.Itoa(i)
+			m, err := tc.GetManyOrLoad(context.Background(), []string{k}, load)
+			if err != nil || m[k] != i {
+				t.Error("GetManyOrLoad returned unexpected result:", m, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Error("concurrent loads were not coalesced; calls:", calls)
+	}
+	if n := tc.ItemCount(); n != 10 {
+		t.Error("Item count is not 10:", n)
+	}
+}
+
+func TestGetManyOrLoadContext(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetBatchWindow(20 * time.Millisecond)
+	load := func(ctx context.Context, ks []string) (map[string]int, error) {
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		m := make(map[string]int, len(ks))
+		for _, k := range ks {
+			m[k] = len(k)
+		}
+		return m, nil
+	}
+
+	// The first caller opens the batch and gives up before it is loaded, the
+	// second one joins it and must not be failed by the first one's deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	errs := make(chan error, 1)
+	go func() {
+		_, err := tc.GetManyOrLoad(ctx, []string{"a"}, load)
+		errs <- err
+	}()
+	time.Sleep(time.Millisecond)
+	m, err := tc.GetManyOrLoad(context.Background(), []string{"bb"}, load)
+	if err != nil || m["bb"] != 2 {
+		t.Error("GetManyOrLoad failed with another caller's context:", m, err)
+	}
+	if err := <-errs; !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("GetManyOrLoad did not return the caller's context error:", err)
+	}
+	if x, found := tc.Get("a"); !found || x != 1 {
+		t.Error("the batch was canceled along with its first caller:", x)
+	}
+
+	tc.SetBatchTimeout(time.Millisecond)
+	if _, err := tc.GetManyOrLoad(context.Background(), []string{"ccc"}, load); !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("batch load did not time out:", err)
+	}
+}
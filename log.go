@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// DefaultLogRateLimit is the default maximum number of records per second the
+// cache logs for each kind of event.
+const DefaultLogRateLimit = 10
+
+type logEvent int
+
+const (
+	logEventSweep logEvent = iota
+	logEventEviction
+	logEventLoadError
+
+	logEvents
+)
+
+// cacheLogger emits the records of a cache, rate limited per kind of event.
+type cacheLogger struct {
+	l        *slog.Logger
+	limiters [logEvents]logLimiter
+}
+
+// logLimiter allows a maximum number of records per second and counts the
+// records it dropped.
+type logLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	second  int64
+	count   int
+	dropped int
+}
+
+// allow returns whether a record can be emitted and the number of records
+// dropped since the last one which was.
+func (l *logLimiter) allow(now time.Time) (bool, int) {
+	if l.limit < 0 {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if s := now.Unix(); s != l.second {
+		l.second = s
+		l.count = 0
+	}
+	if l.count >= l.limit {
+		l.dropped++
+		return false, 0
+	}
+	l.count++
+	dropped := l.dropped
+	l.dropped = 0
+	return true, dropped
+}
+
+func newCacheLogger(l *slog.Logger, limit int) *cacheLogger {
+	if limit == 0 {
+		limit = DefaultLogRateLimit
+	}
+	cl := &cacheLogger{l: l}
+	for i := range cl.limiters {
+		cl.limiters[i].limit = limit
+	}
+	return cl
+}
+
+// enabled reports whether a record of the given level and event would be
+// emitted, so that callers can avoid building its attributes otherwise.
+func (cl *cacheLogger) enabled(level slog.Level) bool {
+	return cl != nil && cl.l.Enabled(context.Background(), level)
+}
+
+func (cl *cacheLogger) log(event logEvent, level slog.Level, msg string, attrs ...slog.Attr) {
+	ok, dropped := cl.limiters[event].allow(time.Now())
+	if !ok {
+		return
+	}
+	if dropped > 0 {
+		attrs = append(attrs, slog.Int("dropped", dropped))
+	}
+	cl.l.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// SetLogger sets an (optional) logger the cache emits structured records to:
+// janitor sweeps and evictions at debug level, loader failures at warn level.
+// At most DefaultLogRateLimit records per second are emitted for each kind of
+// event, see SetLogRateLimit. Set to nil to disable.
+func (c *anyCache[T]) SetLogger(l *slog.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if l == nil {
+		c.logger.Store(nil)
+		return
+	}
+	c.logger.Store(newCacheLogger(l, c.logRateLimit))
+}
+
+// SetLogRateLimit sets the maximum number of records per second the cache logs
+// for each kind of event. If n is 0, DefaultLogRateLimit is used. If n is
+// negative, records are not rate limited.
+func (c *anyCache[T]) SetLogRateLimit(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.logRateLimit = n
+	if cl := c.logger.Load(); cl != nil {
+		c.logger.Store(newCacheLogger(cl.l, n))
+	}
+}
+
+func (c *anyCache[T]) logSweep(scanned, deleted int, d time.Duration) {
+	if cl := c.logger.Load(); cl.enabled(slog.LevelDebug) {
+		cl.log(logEventSweep, slog.LevelDebug, "cache sweep",
+			slog.Int("scanned", scanned),
+			slog.Int("deleted", deleted),
+			slog.Duration("duration", d),
+		)
+	}
+}
+
+// logsEvictions reports whether evictions are logged, so that callers can avoid
+// collecting evicted keys otherwise.
+func (c *anyCache[T]) logsEvictions() bool {
+	return c.logger.Load().enabled(slog.LevelDebug)
+}
+
+func (c *anyCache[T]) logEviction(k string, reason EvictionReason) {
+	if cl := c.logger.Load(); cl.enabled(slog.LevelDebug) {
+		cl.log(logEventEviction, slog.LevelDebug, "cache eviction",
+			slog.String("key", k),
+			slog.String("reason", reason.String()),
+		)
+	}
+}
+
+func (c *anyCache[T]) logLoadError(d time.Duration, err error) {
+	if cl := c.logger.Load(); cl.enabled(slog.LevelWarn) {
+		cl.log(logEventLoadError, slog.LevelWarn, "cache load failed",
+			slog.Duration("duration", d),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		r := make(map[string]any)
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.Set("bar", 2, time.Nanosecond)
+	<-time.After(time.Millisecond)
+	tc.Delete("foo")
+	tc.DeleteExpired()
+	tc.GetManyOrLoad(context.Background(), []string{"baz"}, func(ctx context.Context, ks []string) (map[string]int, error) {
+		return nil, errors.New("load failed")
+	})
+
+	records := decodeRecords(t, buf)
+	if len(records) != 4 {
+		t.Fatal("unexpected number of records:", records)
+	}
+	if r := records[0]; r["msg"] != "cache eviction" || r["key"] != "foo" || r["reason"] != "deleted" {
+		t.Error("unexpected eviction record:", r)
+	}
+	if r := records[1]; r["msg"] != "cache sweep" || r["scanned"] != 1.0 || r["deleted"] != 1.0 {
+		t.Error("unexpected sweep record:", r)
+	}
+	if r := records[2]; r["msg"] != "cache eviction" || r["key"] != "bar" || r["reason"] != "expired" {
+		t.Error("unexpected eviction record:", r)
+	}
+	if r := records[3]; r["msg"] != "cache load failed" || r["level"] != "WARN" || r["error"] != "load failed" {
+		t.Error("unexpected load error record:", r)
+	}
+
+	buf.Reset()
+	tc.SetLogger(nil)
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.Delete("foo")
+	if buf.Len() != 0 {
+		t.Error("records were emitted after the logger was removed:", buf.String())
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.Delete("foo")
+	tc.DeleteExpired()
+	if buf.Len() != 0 {
+		t.Error("debug records were emitted:", buf.String())
+	}
+}
+
+func TestLoggerRateLimit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetLogger(slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	tc.SetLogRateLimit(5)
+
+	for i := 0; i < 100; i++ {
+		tc.Set(strconv.Itoa(i), i, DefaultExpiration)
+	}
+	tc.DeleteFunc(func(k string, v int) bool { return true })
+
+	if n := len(decodeRecords(t, buf)); n > 10 {
+		t.Error("records were not rate limited:", n)
+	}
+
+	l := logLimiter{limit: 1}
+	now := time.Unix(0, 0)
+	if ok, _ := l.allow(now); !ok {
+		t.Error("first record was not allowed")
+	}
+	if ok, _ := l.allow(now); ok {
+		t.Error("second record was allowed")
+	}
+	if ok, dropped := l.allow(now.Add(time.Second)); !ok || dropped != 1 {
+		t.Error("record was not allowed after a second or dropped is not 1:", ok, dropped)
+	}
+}
@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ItemInfo is an item along with its metadata. The metadata is only tracked
+// once EnableMetadata has been called, its fields are zero otherwise.
+type ItemInfo[T any] struct {
+	Item[T]
+	// Created is the time the item was first set.
+	Created time.Time
+	// LastUpdated is the time the item was last set or modified.
+	LastUpdated time.Time
+	// LastAccessed is the time the item was last read, zero if it never was.
+	LastAccessed time.Time
+	// AccessCount is the number of times the item was read.
+	AccessCount uint64
+}
+
+// itemMeta holds the metadata of an item. created and updated are only written
+// with the cache locked for writing, accessed and count are updated atomically
+// by readers.
+type itemMeta struct {
+	created  int64
+	updated  int64
+	accessed int64
+	count    uint64
+}
+
+func (m *itemMeta) access(now int64) {
+	atomic.StoreInt64(&m.accessed, now)
+	atomic.AddUint64(&m.count, 1)
+}
+
+// EnableMetadata enables the tracking of the creation, last update and last
+// access times and of the access count of items, see GetItem and ItemInfos.
+// Items already in the cache have zero creation and update times. Metadata
+// costs an allocation per item and the reads of the cache become slightly more
+// expensive, so it is disabled by default.
+func (c *anyCache[T]) EnableMetadata() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.meta != nil {
+		return
+	}
+	c.meta = make(map[string]*itemMeta, len(c.items))
+	for k := range c.items {
+		c.meta[k] = &itemMeta{}
+	}
+}
+
+// touch records that k was set or modified. The cache must be locked.
+func (c *anyCache[T]) touch(k string, now int64) {
+	m := c.meta[k]
+	if m == nil {
+		m = &itemMeta{created: now}
+		c.meta[k] = m
+	}
+	m.updated = now
+}
+
+// accessed records that k was read. The cache must be locked, for reading at
+// least.
+func (c *anyCache[T]) accessed(k string, now int64) {
+	if m := c.meta[k]; m != nil {
+		m.access(now)
+	}
+}
+
+func (c *anyCache[T]) itemInfo(k string, item Item[T]) ItemInfo[T] {
+	info := ItemInfo[T]{Item: item}
+	if m := c.meta[k]; m != nil {
+		info.Created = unixTime(m.created)
+		info.LastUpdated = unixTime(m.updated)
+		info.LastAccessed = unixTime(atomic.LoadInt64(&m.accessed))
+		info.AccessCount = atomic.LoadUint64(&m.count)
+	}
+	return info
+}
+
+func unixTime(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// GetItem returns an unexpired item along with its metadata, and a bool
+// indicating whether the key was found. It is not counted as an access.
+func (c *anyCache[T]) GetItem(k string) (ItemInfo[T], bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, found := c.items[k]
+	if !found || item.Expired() {
+		return ItemInfo[T]{}, false
+	}
+	return c.itemInfo(k, item), true
+}
+
+// ItemInfos copies all unexpired items in the cache along with their metadata
+// into a new map and returns it. It is the counterpart of Items, whose return
+// type is fixed by the AnyCacher interface.
+func (c *anyCache[T]) ItemInfos() map[string]ItemInfo[T] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m := make(map[string]ItemInfo[T], len(c.items))
+	now := time.Now().UnixNano()
+	for k, v := range c.items {
+		// "Inlining" of Expired
+		if v.Expiration > 0 && now > v.Expiration {
+			continue
+		}
+		m[k] = c.itemInfo(k, v)
+	}
+	return m
+}
@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetadataDisabled(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.Get("foo")
+
+	info, found := tc.GetItem("foo")
+	if !found || info.Object != 1 {
+		t.Fatal("foo was not found:", info)
+	}
+	if !info.Created.IsZero() || info.AccessCount != 0 {
+		t.Error("metadata was tracked although it is disabled:", info)
+	}
+	if tc.meta != nil {
+		t.Error("metadata map was allocated although metadata is disabled")
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+	tc.Set("old", 1, DefaultExpiration)
+	tc.EnableMetadata()
+
+	before := time.Now()
+	tc.Set("foo", 1, DefaultExpiration)
+	info, _ := tc.GetItem("foo")
+	if info.Created.Before(before) || !info.LastUpdated.Equal(info.Created) {
+		t.Error("unexpected creation times:", info)
+	}
+	if !info.LastAccessed.IsZero() || info.AccessCount != 0 {
+		t.Error("foo was accessed:", info)
+	}
+
+	<-time.After(time.Millisecond)
+	tc.Get("foo")
+	tc.GetWithExpiration("foo")
+	tc.GetMany([]string{"foo"})
+	tc.Increment("foo", 1)
+
+	info, _ = tc.GetItem("foo")
+	if info.AccessCount != 3 {
+		t.Error("AccessCount is not 3:", info.AccessCount)
+	}
+	if !info.LastAccessed.After(info.Created) || !info.LastUpdated.After(info.Created) {
+		t.Error("unexpected access and update times:", info)
+	}
+	if info.Object != 2 {
+		t.Error("foo is not 2:", info.Object)
+	}
+
+	infos := tc.ItemInfos()
+	if len(infos) != 2 || !infos["old"].Created.IsZero() || infos["foo"].AccessCount != 3 {
+		t.Error("unexpected item infos:", infos)
+	}
+
+	tc.Delete("foo")
+	tc.Set("foo", 1, DefaultExpiration)
+	info, _ = tc.GetItem("foo")
+	if info.AccessCount != 0 {
+		t.Error("metadata was not deleted along with foo:", info)
+	}
+}
+
+func BenchmarkCacheGetMetadata(b *testing.B) {
+	b.StopTimer()
+	tc := New[string](DefaultExpiration, 0)
+	tc.EnableMetadata()
+	tc.Set("foo", "bar", DefaultExpiration)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tc.Get("foo")
+	}
+}
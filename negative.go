@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"time"
+)
+
+// LookupResult tells what Lookup found in the cache for a key.
+type LookupResult int
+
+const (
+	// Miss means nothing is cached for the key.
+	Miss LookupResult = iota
+	// Hit means an item is cached for the key.
+	Hit
+	// NegativeHit means the key is cached as known to be absent, see SetMissing.
+	NegativeHit
+)
+
+// String implements fmt.Stringer.
+func (r LookupResult) String() string {
+	switch r {
+	case Hit:
+		return "hit"
+	case NegativeHit:
+		return "negative hit"
+	default:
+		return "miss"
+	}
+}
+
+// SetMissingExpiration sets the default expiration of negative entries. It is
+// used by SetMissing when passed DefaultExpiration. If d is not 0, keys which a
+// loader (e.g. the batch loader of GetManyOrLoad) did not return are cached as
+// missing with this expiration.
+func (c *anyCache[T]) SetMissingExpiration(d time.Duration) {
+	c.mu.Lock()
+	c.missingExpiration = d
+	c.mu.Unlock()
+}
+
+// SetMissing caches k as known to be absent, replacing any existing item for
+// the key. If the duration is 0 (DefaultExpiration), the expiration set with
+// SetMissingExpiration is used, or the cache's default expiration if there is
+// none. If it is -1 (NoExpiration), the negative entry never expires.
+//
+// Negative entries are not returned by Get and Items, and are not counted by
+// ItemCount. Use Lookup to tell them apart from keys which are not cached.
+// Setting an item for the key removes its negative entry.
+func (c *anyCache[T]) SetMissing(k string, d time.Duration) {
+	c.mu.Lock()
+	c.setMissing(k, d)
+	c.unlock()
+}
+
+func (c *anyCache[T]) setMissing(k string, d time.Duration) {
+	var e int64
+
+	if d == DefaultExpiration {
+		d = c.missingExpiration
+	}
+
+	if d == DefaultExpiration {
+		d = c.defaultExpiration
+	}
+
+	if d > 0 {
+		e = time.Now().Add(d).UnixNano()
+	}
+
+	if c.missing == nil {
+		c.missing = make(map[string]int64)
+	}
+
+	delete(c.items, k)
+	if c.meta != nil {
+		delete(c.meta, k)
+	}
+	if c.index != nil {
+		c.index.delete(k)
+	}
+	if c.tags != nil {
+		c.tags.untag(k)
+	}
+	if c.deps != nil {
+		c.deps.remove(k)
+		c.cascade(k)
+	}
+	c.missing[k] = e
+}
+
+// setLoadedMissing caches the keys a loader did not return as missing if
+// negative caching of loader results is enabled. The cache must be locked.
+func (c *anyCache[T]) setLoadedMissing(ks []string, loaded map[string]T) {
+	if c.missingExpiration == DefaultExpiration {
+		return
+	}
+	for _, k := range ks {
+		if _, found := loaded[k]; !found {
+			c.setMissing(k, DefaultExpiration)
+		}
+	}
+}
+
+func (c *anyCache[T]) cacheLoadedMissing(ks []string, loaded map[string]T) {
+	c.mu.Lock()
+	c.setLoadedMissing(ks, loaded)
+	c.unlock()
+}
+
+// Lookup gets an item from the cache. It returns the item, or the zero value
+// of T, and whether the key was found, cached as missing or not cached at all.
+func (c *anyCache[T]) Lookup(k string) (T, LookupResult) {
+	c.mu.RLock()
+	x, r := c.lookup(k, time.Now().UnixNano())
+	c.mu.RUnlock()
+
+	switch r {
+	case Hit:
+		c.stats.Load().addHits(1)
+	case NegativeHit:
+		c.stats.Load().addNegativeHits(1)
+	default:
+		c.stats.Load().addMisses(1)
+	}
+
+	return x, r
+}
+
+func (c *anyCache[T]) lookup(k string, now int64) (T, LookupResult) {
+	var ret T
+
+	if item, found := c.items[k]; found {
+		if item.Expiration > 0 && now > item.Expiration {
+			return ret, Miss
+		}
+		if c.meta != nil {
+			c.accessed(k, now)
+		}
+		return item.Object, Hit
+	}
+
+	if e, found := c.missing[k]; found {
+		if e > 0 && now > e {
+			return ret, Miss
+		}
+		return ret, NegativeHit
+	}
+
+	return ret, Miss
+}
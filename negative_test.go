@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetMissing(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.SetMissing("foo", DefaultExpiration)
+	tc.SetMissing("bar", 20*time.Millisecond)
+
+	if x, found := tc.Get("foo"); found {
+		t.Error("foo was found after being set as missing:", x)
+	}
+	if _, r := tc.Lookup("foo"); r != NegativeHit {
+		t.Error("foo lookup is not a negative hit:", r)
+	}
+	if _, r := tc.Lookup("baz"); r != Miss {
+		t.Error("baz lookup is not a miss:", r)
+	}
+	if n := tc.ItemCount(); n != 0 {
+		t.Error("Item count is not 0:", n)
+	}
+
+	<-time.After(30 * time.Millisecond)
+	if _, r := tc.Lookup("bar"); r != Miss {
+		t.Error("expired bar lookup is not a miss:", r)
+	}
+	tc.DeleteExpired()
+	if _, found := tc.missing["bar"]; found {
+		t.Error("expired bar was not deleted")
+	}
+
+	if err := tc.Replace("foo", 2, DefaultExpiration); err == nil {
+		t.Error("Replaced foo although it is missing")
+	}
+	if err := tc.Add("foo", 2, DefaultExpiration); err != nil {
+		t.Error("Couldn't add foo although it is missing:", err)
+	}
+	if x, r := tc.Lookup("foo"); r != Hit || x != 2 {
+		t.Error("foo lookup is not a hit:", x, r)
+	}
+}
+
+func TestGetManyOrLoadMissing(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetMissingExpiration(time.Minute)
+
+	calls := 0
+	load := func(ctx context.Context, ks []string) (map[string]int, error) {
+		calls++
+		return map[string]int{"foo": 1}, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		m, err := tc.GetManyOrLoad(context.Background(), []string{"foo", "bar"}, load)
+		if err != nil || len(m) != 1 || m["foo"] != 1 {
+			t.Error("GetManyOrLoad returned unexpected result:", m, err)
+		}
+	}
+	if calls != 1 {
+		t.Error("bar was loaded again although it is missing; calls:", calls)
+	}
+	if _, r := tc.Lookup("bar"); r != NegativeHit {
+		t.Error("bar lookup is not a negative hit:", r)
+	}
+}
+
+func TestStoreCacheMissing(t *testing.T) {
+	s := newTestStore[int]()
+	c := New[int](DefaultExpiration, 0)
+	c.SetMissingExpiration(time.Minute)
+	tc := NewStoreCache[int](c, s, StoreOptions{ReadThrough: true})
+
+	for i := 0; i < 2; i++ {
+		if _, err := tc.GetContext(context.Background(), "foo"); !errors.Is(err, ErrNotFound) {
+			t.Error("foo error is not ErrNotFound:", err)
+		}
+	}
+	if s.loads != 1 {
+		t.Error("foo was loaded again although it is missing; loads:", s.loads)
+	}
+}
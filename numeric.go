@@ -0,0 +1,411 @@
+package cache
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// isFloat reports whether T is a floating-point type.
+func isFloat[T Numeric]() bool {
+	half := 0.5
+	return T(half) != 0
+}
+
+// isSigned reports whether T is a signed type.
+func isSigned[T Numeric]() bool {
+	var zero T
+	return zero-1 < zero
+}
+
+// numericBounds returns the lowest and highest finite values of T.
+func numericBounds[T Numeric]() (min, max T) {
+	var zero T
+	bits := 8 * unsafe.Sizeof(zero)
+	switch {
+	case isFloat[T]():
+		m := math.MaxFloat64
+		if bits == 32 {
+			m = math.MaxFloat32
+		}
+		return T(-m), T(m)
+	case isSigned[T]():
+		m := int64(1)<<(bits-1) - 1
+		return T(-m - 1), T(m)
+	default:
+		m := uint64(1)<<bits - 1
+		return 0, T(m)
+	}
+}
+
+// notFinite reports whether x is an infinity or NaN. It is always false for
+// integers.
+func notFinite[T Numeric](x T) bool {
+	return x-x != 0
+}
+
+// addChecked returns a+n and whether the addition overflowed T. Floats overflow
+// when the result is not finite.
+func addChecked[T Numeric](a, n T) (T, bool) {
+	r := a + n
+	if isFloat[T]() {
+		return r, notFinite(r)
+	}
+	var zero T
+	return r, (n > zero && r < a) || (n < zero && r > a)
+}
+
+// subChecked returns a-n and whether the subtraction overflowed T. Floats
+// overflow when the result is not finite.
+func subChecked[T Numeric](a, n T) (T, bool) {
+	r := a - n
+	if isFloat[T]() {
+		return r, notFinite(r)
+	}
+	var zero T
+	return r, (n > zero && r > a) || (n < zero && r < a)
+}
+
+// saturate clamps the result r of an operation which overflowed to the bounds
+// of T, the upper one if the operation was going up. It returns false if r is
+// NaN, which cannot be clamped.
+func saturate[T Numeric](r T, overflowed, up bool) (T, bool) {
+	if !overflowed {
+		return r, true
+	}
+	if r != r {
+		return r, false
+	}
+	if isFloat[T]() {
+		up = r > 0
+	}
+	min, max := numericBounds[T]()
+	if up {
+		return max, true
+	}
+	return min, true
+}
+
+// clamp clamps x to [min, max].
+func clamp[T Numeric](x, min, max T) T {
+	if x > max {
+		return max
+	}
+	if x < min {
+		return min
+	}
+	return x
+}
+
+// modify replaces the value of an unexpired item with the one returned by f,
+// keeping its expiration, and returns it. If f returns an error, the item is
+// left untouched.
+func (c *numericCache[T]) modify(k string, f func(v T) (T, error)) (T, error) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		var ret T
+		return ret, keyError(k, ErrNotFound)
+	}
+
+	nv, err := f(v.Object)
+	if err != nil {
+		var ret T
+		return ret, keyError(k, err)
+	}
+	v.Object = nv
+	c.updateItem(k, v)
+
+	return nv, nil
+}
+
+// IncrementChecked increments an item by n like Increment, but returns
+// ErrOverflow and leaves the item untouched if the result overflows its type.
+// For floats, a result which is infinite or NaN is an overflow.
+func (c *numericCache[T]) IncrementChecked(k string, n T) (T, error) {
+	return c.modify(k, func(v T) (T, error) {
+		nv, overflowed := addChecked(v, n)
+		if overflowed {
+			return nv, ErrOverflow
+		}
+		return nv, nil
+	})
+}
+
+// DecrementChecked decrements an item by n like Decrement, but returns
+// ErrOverflow and leaves the item untouched if the result overflows its type.
+// For floats, a result which is infinite or NaN is an overflow.
+func (c *numericCache[T]) DecrementChecked(k string, n T) (T, error) {
+	return c.modify(k, func(v T) (T, error) {
+		nv, overflowed := subChecked(v, n)
+		if overflowed {
+			return nv, ErrOverflow
+		}
+		return nv, nil
+	})
+}
+
+// IncrementSaturating increments an item by n like Increment, but clamps the
+// result to the bounds of its type instead of wrapping around. For floats,
+// infinities are clamped to the highest or lowest finite value, and ErrOverflow
+// is returned if the result is NaN.
+func (c *numericCache[T]) IncrementSaturating(k string, n T) (T, error) {
+	var zero T
+	return c.modify(k, func(v T) (T, error) {
+		nv, overflowed := addChecked(v, n)
+		nv, ok := saturate(nv, overflowed, n > zero)
+		if !ok {
+			return nv, ErrOverflow
+		}
+		return nv, nil
+	})
+}
+
+// DecrementSaturating decrements an item by n like Decrement, but clamps the
+// result to the bounds of its type instead of wrapping around. For floats,
+// infinities are clamped to the highest or lowest finite value, and ErrOverflow
+// is returned if the result is NaN.
+func (c *numericCache[T]) DecrementSaturating(k string, n T) (T, error) {
+	var zero T
+	return c.modify(k, func(v T) (T, error) {
+		nv, overflowed := subChecked(v, n)
+		nv, ok := saturate(nv, overflowed, n < zero)
+		if !ok {
+			return nv, ErrOverflow
+		}
+		return nv, nil
+	})
+}
+
+// IncrementBounded increments an item by n and clamps the result to [min, max].
+// The item's current value does not need to be within the bounds. ErrOverflow
+// is returned if the result is NaN.
+func (c *numericCache[T]) IncrementBounded(k string, n, min, max T) (T, error) {
+	var zero T
+	return c.modify(k, func(v T) (T, error) {
+		nv, overflowed := addChecked(v, n)
+		nv, ok := saturate(nv, overflowed, n > zero)
+		if !ok {
+			return nv, ErrOverflow
+		}
+		return clamp(nv, min, max), nil
+	})
+}
+
+// DecrementBounded decrements an item by n and clamps the result to [min, max].
+// The item's current value does not need to be within the bounds. ErrOverflow
+// is returned if the result is NaN.
+func (c *numericCache[T]) DecrementBounded(k string, n, min, max T) (T, error) {
+	var zero T
+	return c.modify(k, func(v T) (T, error) {
+		nv, overflowed := subChecked(v, n)
+		nv, ok := saturate(nv, overflowed, n < zero)
+		if !ok {
+			return nv, ErrOverflow
+		}
+		return clamp(nv, min, max), nil
+	})
+}
+
+// addOrSet replaces the value of an unexpired item with the one returned by f,
+// or sets it to n with the duration d if it does not exist or has expired, and
+// returns the new value. If refresh is true, the expiration of an existing item
+// is reset using d.
+func (c *numericCache[T]) addOrSet(k string, n T, d time.Duration, refresh bool, f func(v T) T) T {
+	c.mu.Lock()
+	defer c.unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		c.set(k, n, d)
+		return n
+	}
+
+	v.Object = f(v.Object)
+	if refresh {
+		v.Expiration = c.expiration(d)
+	}
+	c.updateItem(k, v)
+
+	return v.Object
+}
+
+// IncrementOrSet atomically increments an item by n, or sets it to n with the
+// duration d if it does not exist or has expired, and returns the new value.
+// The expiration of an existing item is kept, which suits fixed-window
+// counters; see IncrementOrSetRefresh to extend it. The duration is handled as
+// in Set.
+func (c *numericCache[T]) IncrementOrSet(k string, n T, d time.Duration) T {
+	return c.addOrSet(k, n, d, false, func(v T) T { return v + n })
+}
+
+// IncrementOrSetRefresh is like IncrementOrSet but also resets the expiration
+// of an existing item using d, so that the item expires d after its last
+// increment.
+func (c *numericCache[T]) IncrementOrSetRefresh(k string, n T, d time.Duration) T {
+	return c.addOrSet(k, n, d, true, func(v T) T { return v + n })
+}
+
+// DecrementOrSet atomically decrements an item by n, or sets it to n with the
+// duration d if it does not exist or has expired, and returns the new value.
+// The expiration of an existing item is kept, see DecrementOrSetRefresh
+// otherwise. The duration is handled as in Set.
+func (c *numericCache[T]) DecrementOrSet(k string, n T, d time.Duration) T {
+	return c.addOrSet(k, n, d, false, func(v T) T { return v - n })
+}
+
+// DecrementOrSetRefresh is like DecrementOrSet but also resets the expiration
+// of an existing item using d, so that the item expires d after its last
+// decrement.
+func (c *numericCache[T]) DecrementOrSetRefresh(k string, n T, d time.Duration) T {
+	return c.addOrSet(k, n, d, true, func(v T) T { return v - n })
+}
+
+// Multiply multiplies an item by n and returns the result, wrapping around on
+// overflow like Increment. Returns ErrNotFound if the item was not found or has
+// expired.
+func (c *numericCache[T]) Multiply(k string, n T) (T, error) {
+	return c.modify(k, func(v T) (T, error) {
+		return v * n, nil
+	})
+}
+
+// Divide divides an item by n and returns the result. Integers are truncated
+// towards zero. Returns ErrDivisionByZero and leaves the item untouched if n is
+// zero, ErrNotFound if the item was not found or has expired.
+func (c *numericCache[T]) Divide(k string, n T) (T, error) {
+	return c.modify(k, func(v T) (T, error) {
+		if n == 0 {
+			return v, ErrDivisionByZero
+		}
+		return v / n, nil
+	})
+}
+
+// setIf sets an item to x if it does not exist, has expired or if keep returns
+// false for its current value. The expiration of an existing item is kept, the
+// duration d is used, as in Set, for a new one. It returns the value of the item
+// and whether it was set.
+func (c *numericCache[T]) setIf(k string, x T, d time.Duration, keep func(v T) bool) (T, bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	v, found := c.items[k]
+	if !found || v.Expired() {
+		c.set(k, x, d)
+		return x, true
+	}
+	if keep(v.Object) {
+		return v.Object, false
+	}
+	c.replaceObject(k, x)
+	return x, true
+}
+
+// SetMax sets an item to x if x is greater than its current value, or if the
+// item does not exist or has expired, in which case the duration d is used as
+// in Set. The expiration of an existing item is kept. It returns the value of
+// the item and whether x was stored.
+func (c *numericCache[T]) SetMax(k string, x T, d time.Duration) (T, bool) {
+	return c.setIf(k, x, d, func(v T) bool { return !(x > v) })
+}
+
+// SetMin sets an item to x if x is lower than its current value, or if the
+// item does not exist or has expired, in which case the duration d is used as
+// in Set. The expiration of an existing item is kept. It returns the value of
+// the item and whether x was stored.
+func (c *numericCache[T]) SetMin(k string, x T, d time.Duration) (T, bool) {
+	return c.setIf(k, x, d, func(v T) bool { return !(x < v) })
+}
+
+// GetAndReset atomically gets an item and sets it to zero, keeping its
+// expiration. Returns the previous value of the item and a bool indicating
+// whether the key was found.
+func (c *numericCache[T]) GetAndReset(k string) (T, bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	v, found := c.get(k)
+	if !found {
+		var ret T
+		return ret, false
+	}
+	c.replaceObject(k, 0)
+	return v, true
+}
+
+// aggregate calls f with the value of every unexpired item whose key starts
+// with prefix, all of them if prefix is empty.
+func (c *numericCache[T]) aggregate(prefix string, f func(v T)) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now().UnixNano()
+	for k, v := range c.items {
+		// "Inlining" of Expired
+		if v.Expiration > 0 && now > v.Expiration {
+			continue
+		}
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		f(v.Object)
+	}
+}
+
+// Sum returns the sum of the unexpired items whose key starts with prefix, of
+// all of them if prefix is empty. Integers wrap around on overflow.
+func (c *numericCache[T]) Sum(prefix string) T {
+	var sum T
+	c.aggregate(prefix, func(v T) {
+		sum += v
+	})
+	return sum
+}
+
+// Min returns the lowest value of the unexpired items whose key starts with
+// prefix, of all of them if prefix is empty, and false if there is none.
+func (c *numericCache[T]) Min(prefix string) (T, bool) {
+	var min T
+	found := false
+	c.aggregate(prefix, func(v T) {
+		if !found || v < min {
+			min = v
+			found = true
+		}
+	})
+	return min, found
+}
+
+// Max returns the highest value of the unexpired items whose key starts with
+// prefix, of all of them if prefix is empty, and false if there is none.
+func (c *numericCache[T]) Max(prefix string) (T, bool) {
+	var max T
+	found := false
+	c.aggregate(prefix, func(v T) {
+		if !found || v > max {
+			max = v
+			found = true
+		}
+	})
+	return max, found
+}
+
+// Mean returns the arithmetic mean of the unexpired items whose key starts
+// with prefix, of all of them if prefix is empty, and false if there is none.
+// It is computed with float64 so that the sum of integers does not overflow.
+func (c *numericCache[T]) Mean(prefix string) (float64, bool) {
+	var sum float64
+	n := 0
+	c.aggregate(prefix, func(v T) {
+		sum += float64(v)
+		n++
+	})
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
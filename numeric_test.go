@@ -0,0 +1,319 @@
+package cache
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+type quota int16
+
+func testNumericBounds[T Numeric](t *testing.T, name string, wantMin, wantMax T) {
+	t.Helper()
+	min, max := numericBounds[T]()
+	if min != wantMin || max != wantMax {
+		t.Errorf("%s: bounds are [%v, %v], want [%v, %v]", name, min, max, wantMin, wantMax)
+	}
+}
+
+func TestNumericBounds(t *testing.T) {
+	testNumericBounds[int8](t, "int8", math.MinInt8, math.MaxInt8)
+	testNumericBounds[int16](t, "int16", math.MinInt16, math.MaxInt16)
+	testNumericBounds[int32](t, "int32", math.MinInt32, math.MaxInt32)
+	testNumericBounds[int64](t, "int64", math.MinInt64, math.MaxInt64)
+	testNumericBounds[int](t, "int", math.MinInt, math.MaxInt)
+	testNumericBounds[quota](t, "quota", math.MinInt16, math.MaxInt16)
+	testNumericBounds[uint8](t, "uint8", 0, math.MaxUint8)
+	testNumericBounds[uint16](t, "uint16", 0, math.MaxUint16)
+	testNumericBounds[uint32](t, "uint32", 0, math.MaxUint32)
+	testNumericBounds[uint64](t, "uint64", 0, math.MaxUint64)
+	testNumericBounds[uint](t, "uint", 0, math.MaxUint)
+	testNumericBounds[uintptr](t, "uintptr", 0, ^uintptr(0))
+	testNumericBounds[float32](t, "float32", -math.MaxFloat32, math.MaxFloat32)
+	testNumericBounds[float64](t, "float64", -math.MaxFloat64, math.MaxFloat64)
+}
+
+func testCheckedOverflow[T Numeric](t *testing.T, name string) {
+	t.Helper()
+	min, max := numericBounds[T]()
+	tc := NewNumeric[T](DefaultExpiration, 0)
+
+	tc.Set("max", max, DefaultExpiration)
+	if _, err := tc.IncrementChecked("max", max); !errors.Is(err, ErrOverflow) {
+		t.Errorf("%s: IncrementChecked did not overflow: %v", name, err)
+	}
+	if x, _ := tc.Get("max"); x != max {
+		t.Errorf("%s: IncrementChecked modified the item: %v", name, x)
+	}
+	if x, err := tc.IncrementSaturating("max", max); err != nil || x != max {
+		t.Errorf("%s: IncrementSaturating did not saturate: %v, %v", name, x, err)
+	}
+
+	tc.Set("min", min, DefaultExpiration)
+	if _, err := tc.DecrementChecked("min", max); !errors.Is(err, ErrOverflow) {
+		t.Errorf("%s: DecrementChecked did not overflow: %v", name, err)
+	}
+	if x, err := tc.DecrementSaturating("min", max); err != nil || x != min {
+		t.Errorf("%s: DecrementSaturating did not saturate: %v, %v", name, x, err)
+	}
+
+	tc.Set("one", 1, DefaultExpiration)
+	if x, err := tc.IncrementChecked("one", 1); err != nil || x != 2 {
+		t.Errorf("%s: IncrementChecked failed: %v, %v", name, x, err)
+	}
+	if x, err := tc.DecrementChecked("one", 2); err != nil || x != 0 {
+		t.Errorf("%s: DecrementChecked failed: %v, %v", name, x, err)
+	}
+}
+
+func TestCheckedOverflow(t *testing.T) {
+	testCheckedOverflow[int8](t, "int8")
+	testCheckedOverflow[int16](t, "int16")
+	testCheckedOverflow[int32](t, "int32")
+	testCheckedOverflow[int64](t, "int64")
+	testCheckedOverflow[int](t, "int")
+	testCheckedOverflow[quota](t, "quota")
+	testCheckedOverflow[uint8](t, "uint8")
+	testCheckedOverflow[uint16](t, "uint16")
+	testCheckedOverflow[uint32](t, "uint32")
+	testCheckedOverflow[uint64](t, "uint64")
+	testCheckedOverflow[uint](t, "uint")
+	testCheckedOverflow[uintptr](t, "uintptr")
+	testCheckedOverflow[float32](t, "float32")
+	testCheckedOverflow[float64](t, "float64")
+}
+
+func TestCheckedNegative(t *testing.T) {
+	tc := NewNumeric[int8](DefaultExpiration, 0)
+	tc.Set("foo", math.MinInt8, DefaultExpiration)
+	if _, err := tc.IncrementChecked("foo", -1); !errors.Is(err, ErrOverflow) {
+		t.Error("IncrementChecked by a negative value did not overflow:", err)
+	}
+	if x, _ := tc.IncrementSaturating("foo", -1); x != math.MinInt8 {
+		t.Error("IncrementSaturating by a negative value did not saturate:", x)
+	}
+	tc.Set("foo", math.MaxInt8, DefaultExpiration)
+	if x, _ := tc.DecrementSaturating("foo", -1); x != math.MaxInt8 {
+		t.Error("DecrementSaturating by a negative value did not saturate:", x)
+	}
+
+	if _, err := tc.IncrementChecked("bar", 1); !errors.Is(err, ErrNotFound) {
+		t.Error("IncrementChecked did not return ErrNotFound:", err)
+	}
+}
+
+func TestCheckedFloat(t *testing.T) {
+	tc := NewNumeric[float64](DefaultExpiration, 0)
+
+	tc.Set("inf", math.Inf(1), DefaultExpiration)
+	if _, err := tc.IncrementChecked("inf", 1); !errors.Is(err, ErrOverflow) {
+		t.Error("IncrementChecked of an infinity did not overflow:", err)
+	}
+	if x, err := tc.DecrementSaturating("inf", 1); err != nil || x != math.MaxFloat64 {
+		t.Error("DecrementSaturating did not clamp an infinity:", x, err)
+	}
+
+	tc.Set("foo", 1, DefaultExpiration)
+	if _, err := tc.IncrementChecked("foo", math.NaN()); !errors.Is(err, ErrOverflow) {
+		t.Error("IncrementChecked by NaN did not overflow:", err)
+	}
+	if _, err := tc.IncrementSaturating("foo", math.NaN()); !errors.Is(err, ErrOverflow) {
+		t.Error("IncrementSaturating by NaN did not fail:", err)
+	}
+	if _, err := tc.IncrementBounded("foo", math.NaN(), 0, 10); !errors.Is(err, ErrOverflow) {
+		t.Error("IncrementBounded by NaN did not fail:", err)
+	}
+	if x, _ := tc.Get("foo"); x != 1 {
+		t.Error("foo was modified:", x)
+	}
+	if x, _ := tc.IncrementSaturating("foo", math.Inf(-1)); x != -math.MaxFloat64 {
+		t.Error("IncrementSaturating did not clamp to the lowest float:", x)
+	}
+}
+
+func TestIncrementBounded(t *testing.T) {
+	tc := NewNumeric[uint8](DefaultExpiration, 0)
+	tc.Set("quota", 8, DefaultExpiration)
+
+	if x, err := tc.IncrementBounded("quota", 1, 0, 10); err != nil || x != 9 {
+		t.Error("IncrementBounded failed:", x, err)
+	}
+	if x, _ := tc.IncrementBounded("quota", 5, 0, 10); x != 10 {
+		t.Error("IncrementBounded did not clamp to max:", x)
+	}
+	if x, _ := tc.IncrementBounded("quota", 255, 0, 10); x != 10 {
+		t.Error("IncrementBounded did not clamp an overflow to max:", x)
+	}
+	if x, _ := tc.DecrementBounded("quota", 20, 2, 10); x != 2 {
+		t.Error("DecrementBounded did not clamp an underflow to min:", x)
+	}
+
+	ti := NewNumeric[int](DefaultExpiration, 0)
+	ti.Set("foo", 0, DefaultExpiration)
+	if x, _ := ti.IncrementBounded("foo", -5, -3, 3); x != -3 {
+		t.Error("IncrementBounded did not clamp to min:", x)
+	}
+	if x, _ := ti.DecrementBounded("foo", -10, -3, 3); x != 3 {
+		t.Error("DecrementBounded did not clamp to max:", x)
+	}
+}
+
+func TestIncrementOrSet(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+
+	if x := tc.IncrementOrSet("foo", 2, 50*time.Millisecond); x != 2 {
+		t.Error("IncrementOrSet did not set foo:", x)
+	}
+	_, e1, _ := tc.GetWithExpiration("foo")
+	<-time.After(time.Millisecond)
+	if x := tc.IncrementOrSet("foo", 3, 50*time.Millisecond); x != 5 {
+		t.Error("IncrementOrSet did not increment foo:", x)
+	}
+	_, e2, _ := tc.GetWithExpiration("foo")
+	if !e1.Equal(e2) {
+		t.Error("IncrementOrSet did not keep the expiration of foo:", e1, e2)
+	}
+	if x := tc.IncrementOrSetRefresh("foo", 1, 50*time.Millisecond); x != 6 {
+		t.Error("IncrementOrSetRefresh did not increment foo:", x)
+	}
+	_, e3, _ := tc.GetWithExpiration("foo")
+	if !e3.After(e2) {
+		t.Error("IncrementOrSetRefresh did not refresh the expiration of foo:", e2, e3)
+	}
+	if x := tc.DecrementOrSet("foo", 4, 50*time.Millisecond); x != 2 {
+		t.Error("DecrementOrSet did not decrement foo:", x)
+	}
+	if x := tc.DecrementOrSetRefresh("bar", 4, DefaultExpiration); x != 4 {
+		t.Error("DecrementOrSetRefresh did not set bar:", x)
+	}
+
+	tc.Set("expired", 10, time.Nanosecond)
+	<-time.After(time.Millisecond)
+	if x := tc.IncrementOrSet("expired", 1, DefaultExpiration); x != 1 {
+		t.Error("IncrementOrSet incremented an expired item:", x)
+	}
+}
+
+func TestIncrementOrSetConcurrent(t *testing.T) {
+	tc := NewNumeric[int64](DefaultExpiration, 0)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tc.IncrementOrSet("counter", 1, time.Minute)
+		}()
+	}
+	wg.Wait()
+	if x, _ := tc.Get("counter"); x != 100 {
+		t.Error("concurrent IncrementOrSet lost increments:", x)
+	}
+}
+
+func TestMultiplyDivide(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+	tc.Set("foo", 6, DefaultExpiration)
+
+	if x, err := tc.Multiply("foo", 7); err != nil || x != 42 {
+		t.Error("Multiply failed:", x, err)
+	}
+	if x, err := tc.Divide("foo", 4); err != nil || x != 10 {
+		t.Error("Divide failed:", x, err)
+	}
+	if _, err := tc.Divide("foo", 0); !errors.Is(err, ErrDivisionByZero) {
+		t.Error("Divide by zero did not fail:", err)
+	}
+	if x, _ := tc.Get("foo"); x != 10 {
+		t.Error("Divide by zero modified foo:", x)
+	}
+	if _, err := tc.Multiply("bar", 2); !errors.Is(err, ErrNotFound) {
+		t.Error("Multiply did not return ErrNotFound:", err)
+	}
+
+	tf := NewNumeric[float32](DefaultExpiration, 0)
+	tf.Set("foo", 1, DefaultExpiration)
+	if x, _ := tf.Divide("foo", 4); x != 0.25 {
+		t.Error("Divide failed:", x)
+	}
+	if _, err := tf.Divide("foo", 0); !errors.Is(err, ErrDivisionByZero) {
+		t.Error("Divide by zero did not fail:", err)
+	}
+}
+
+func TestSetMaxMin(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+
+	if x, stored := tc.SetMax("max", 5, 50*time.Millisecond); x != 5 || !stored {
+		t.Error("SetMax did not set max:", x, stored)
+	}
+	_, e1, _ := tc.GetWithExpiration("max")
+	if x, stored := tc.SetMax("max", 3, DefaultExpiration); x != 5 || stored {
+		t.Error("SetMax stored a lower value:", x, stored)
+	}
+	if x, stored := tc.SetMax("max", 8, DefaultExpiration); x != 8 || !stored {
+		t.Error("SetMax did not store a greater value:", x, stored)
+	}
+	if _, e2, _ := tc.GetWithExpiration("max"); !e1.Equal(e2) {
+		t.Error("SetMax did not keep the expiration:", e1, e2)
+	}
+
+	tc.SetMin("min", 5, DefaultExpiration)
+	if x, stored := tc.SetMin("min", 8, DefaultExpiration); x != 5 || stored {
+		t.Error("SetMin stored a greater value:", x, stored)
+	}
+	if x, stored := tc.SetMin("min", -1, DefaultExpiration); x != -1 || !stored {
+		t.Error("SetMin did not store a lower value:", x, stored)
+	}
+}
+
+func TestGetAndReset(t *testing.T) {
+	tc := NewNumeric[uint64](DefaultExpiration, 0)
+	tc.Set("foo", 42, DefaultExpiration)
+
+	if x, found := tc.GetAndReset("foo"); x != 42 || !found {
+		t.Error("GetAndReset failed:", x, found)
+	}
+	if x, found := tc.Get("foo"); x != 0 || !found {
+		t.Error("foo was not reset:", x, found)
+	}
+	if _, found := tc.GetAndReset("bar"); found {
+		t.Error("GetAndReset found a missing key")
+	}
+}
+
+func TestAggregates(t *testing.T) {
+	tc := NewNumeric[int8](DefaultExpiration, 0)
+
+	if _, found := tc.Min(""); found {
+		t.Error("Min found a value in an empty cache")
+	}
+	if _, found := tc.Mean(""); found {
+		t.Error("Mean found a value in an empty cache")
+	}
+
+	tc.Set("a:1", 100, DefaultExpiration)
+	tc.Set("a:2", 100, DefaultExpiration)
+	tc.Set("a:3", -20, DefaultExpiration)
+	tc.Set("b:1", 7, DefaultExpiration)
+	tc.Set("a:expired", 1, time.Nanosecond)
+	<-time.After(time.Millisecond)
+
+	if x := tc.Sum("b:"); x != 7 {
+		t.Error("unexpected sum:", x)
+	}
+	if x, _ := tc.Min("a:"); x != -20 {
+		t.Error("unexpected min:", x)
+	}
+	if x, _ := tc.Max(""); x != 100 {
+		t.Error("unexpected max:", x)
+	}
+	// The mean does not overflow even though the sum of the items does.
+	if x, _ := tc.Mean("a:"); x != 60 {
+		t.Error("unexpected mean:", x)
+	}
+	if x, found := tc.Mean("c:"); found {
+		t.Error("Mean found a value for a missing prefix:", x)
+	}
+}
@@ -0,0 +1,286 @@
+package otel
+
+import (
+	"context"
+	"time"
+
+	"sylr.dev/cache/v3"
+)
+
+// AnyCache decorates an AnyCacher, recording metrics for its operations and
+// spans for its loads and bulk operations.
+type AnyCache[T any] struct {
+	cache.AnyCacher[T]
+	inst *instruments
+}
+
+var _ cache.AnyCacher[any] = (*AnyCache[any])(nil)
+
+// NewAnyCache returns a new *AnyCache[T] decorating c.
+func NewAnyCache[T any](c cache.AnyCacher[T], opts Options) (*AnyCache[T], error) {
+	inst, err := newInstruments(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &AnyCache[T]{AnyCacher: c, inst: inst}, nil
+}
+
+// Close unregisters the callback of the hit ratio gauge. The cache must not be
+// used after Close.
+func (c *AnyCache[T]) Close() error {
+	return c.inst.reg.Unregister()
+}
+
+// Get gets an item from the cache. Returns the item or nil, and a bool indicating
+// whether the key was found.
+func (c *AnyCache[T]) Get(k string) (T, bool) {
+	start := time.Now()
+	x, ok := c.AnyCacher.Get(k)
+	c.inst.record(context.Background(), "Get", k, found(ok), start)
+	return x, ok
+}
+
+// GetWithExpiration returns an item and its expiration time from the cache.
+func (c *AnyCache[T]) GetWithExpiration(k string) (T, time.Time, bool) {
+	start := time.Now()
+	x, e, ok := c.AnyCacher.GetWithExpiration(k)
+	c.inst.record(context.Background(), "GetWithExpiration", k, found(ok), start)
+	return x, e, ok
+}
+
+// Set adds an item to the cache, replacing any existing item.
+func (c *AnyCache[T]) Set(k string, x T, d time.Duration) {
+	start := time.Now()
+	c.AnyCacher.Set(k, x, d)
+	c.inst.record(context.Background(), "Set", k, ResultOK, start)
+}
+
+// SetDefault adds an item to the cache, replacing any existing item, using the
+// default expiration.
+func (c *AnyCache[T]) SetDefault(k string, x T) {
+	start := time.Now()
+	c.AnyCacher.SetDefault(k, x)
+	c.inst.record(context.Background(), "SetDefault", k, ResultOK, start)
+}
+
+// Add an item to the cache only if an item doesn't already exist for the given
+// key, or if the existing item has expired. Returns an error otherwise.
+func (c *AnyCache[T]) Add(k string, x T, d time.Duration) error {
+	start := time.Now()
+	err := c.AnyCacher.Add(k, x, d)
+	c.inst.record(context.Background(), "Add", k, result(err), start)
+	return err
+}
+
+// Replace replaces a new value for the cache key only if it already exists, and
+// the existing item hasn't expired. Returns an error otherwise.
+func (c *AnyCache[T]) Replace(k string, x T, d time.Duration) error {
+	start := time.Now()
+	err := c.AnyCacher.Replace(k, x, d)
+	c.inst.record(context.Background(), "Replace", k, result(err), start)
+	return err
+}
+
+// Delete deletes an item from the cache.
+func (c *AnyCache[T]) Delete(k string) {
+	start := time.Now()
+	c.AnyCacher.Delete(k)
+	c.inst.record(context.Background(), "Delete", k, ResultOK, start)
+}
+
+// DeleteExpired deletes all expired items from the cache.
+func (c *AnyCache[T]) DeleteExpired() {
+	start := time.Now()
+	c.AnyCacher.DeleteExpired()
+	c.inst.record(context.Background(), "DeleteExpired", "", ResultOK, start)
+}
+
+// Flush deletes all items from the cache.
+func (c *AnyCache[T]) Flush() {
+	start := time.Now()
+	c.AnyCacher.Flush()
+	c.inst.record(context.Background(), "Flush", "", ResultOK, start)
+}
+
+type getManyer[T any] interface {
+	GetMany(ks []string) map[string]T
+}
+
+type getManyOrLoader[T any] interface {
+	GetManyOrLoad(ctx context.Context, ks []string, load cache.BatchLoaderFunc[T]) (map[string]T, error)
+}
+
+type setManyer[T any] interface {
+	SetMany(items map[string]T, d time.Duration) int
+}
+
+type deleteManyer interface {
+	DeleteMany(ks []string) int
+}
+
+var (
+	_ getManyer[any]       = (*AnyCache[any])(nil)
+	_ getManyOrLoader[any] = (*AnyCache[any])(nil)
+	_ setManyer[any]       = (*AnyCache[any])(nil)
+	_ deleteManyer         = (*AnyCache[any])(nil)
+)
+
+// GetMany gets the given keys from the cache within a span. If the decorated
+// cache does not implement GetMany, the keys are fetched one by one.
+func (c *AnyCache[T]) GetMany(ks []string) map[string]T {
+	return c.GetManyContext(context.Background(), ks)
+}
+
+// GetManyContext is like GetMany but starts its span from ctx.
+func (c *AnyCache[T]) GetManyContext(ctx context.Context, ks []string) map[string]T {
+	ctx, span := c.inst.startSpan(ctx, "GetMany", len(ks))
+	defer span.End()
+
+	start := time.Now()
+	var m map[string]T
+	if gm, ok := c.AnyCacher.(getManyer[T]); ok {
+		m = gm.GetMany(ks)
+	} else {
+		m = make(map[string]T, len(ks))
+		for _, k := range ks {
+			if x, found := c.AnyCacher.Get(k); found {
+				m[k] = x
+			}
+		}
+	}
+	c.inst.recordHits(len(m), len(ks)-len(m))
+	c.inst.record(ctx, "GetMany", "", ResultOK, start)
+	return m
+}
+
+// GetManyOrLoad gets the given keys from the cache and loads the missing ones
+// using load, within a span. The loader is called within a child span of the
+// span of the call which opened the batch. If the decorated cache does not
+// implement GetManyOrLoad, the missing keys are loaded without being coalesced
+// with those of concurrent callers and are cached with the default expiration.
+func (c *AnyCache[T]) GetManyOrLoad(ctx context.Context, ks []string, load cache.BatchLoaderFunc[T]) (map[string]T, error) {
+	ctx, span := c.inst.startSpan(ctx, "GetManyOrLoad", len(ks))
+	start := time.Now()
+
+	traced := func(ctx context.Context, ks []string) (map[string]T, error) {
+		ctx, span := c.inst.startSpan(ctx, "Load", len(ks))
+		start := time.Now()
+		m, err := load(ctx, ks)
+		c.inst.record(ctx, "Load", "", result(err), start)
+		endSpan(span, err)
+		return m, err
+	}
+
+	var m map[string]T
+	var err error
+	if gml, ok := c.AnyCacher.(getManyOrLoader[T]); ok {
+		m, err = gml.GetManyOrLoad(ctx, ks, traced)
+	} else {
+		m = c.GetManyContext(ctx, ks)
+		var missing []string
+		for _, k := range ks {
+			if _, found := m[k]; !found {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) > 0 {
+			var loaded map[string]T
+			loaded, err = traced(ctx, missing)
+			for k, x := range loaded {
+				c.AnyCacher.SetDefault(k, x)
+				m[k] = x
+			}
+		}
+	}
+
+	c.inst.record(ctx, "GetManyOrLoad", "", result(err), start)
+	endSpan(span, err)
+	return m, err
+}
+
+// SetMany adds all the given items to the cache within a span and returns the
+// number of items set. If the decorated cache does not implement SetMany, the
+// items are set one by one.
+func (c *AnyCache[T]) SetMany(items map[string]T, d time.Duration) int {
+	return c.SetManyContext(context.Background(), items, d)
+}
+
+// SetManyContext is like SetMany but starts its span from ctx.
+func (c *AnyCache[T]) SetManyContext(ctx context.Context, items map[string]T, d time.Duration) int {
+	ctx, span := c.inst.startSpan(ctx, "SetMany", len(items))
+	defer span.End()
+
+	start := time.Now()
+	var n int
+	if sm, ok := c.AnyCacher.(setManyer[T]); ok {
+		n = sm.SetMany(items, d)
+	} else {
+		for k, x := range items {
+			c.AnyCacher.Set(k, x, d)
+		}
+		n = len(items)
+	}
+	c.inst.record(ctx, "SetMany", "", ResultOK, start)
+	return n
+}
+
+// DeleteMany deletes the given keys from the cache within a span and returns
+// the number of items deleted. If the decorated cache does not implement
+// DeleteMany, the keys are deleted one by one and the number of keys is
+// returned.
+func (c *AnyCache[T]) DeleteMany(ks []string) int {
+	return c.DeleteManyContext(context.Background(), ks)
+}
+
+// DeleteManyContext is like DeleteMany but starts its span from ctx.
+func (c *AnyCache[T]) DeleteManyContext(ctx context.Context, ks []string) int {
+	ctx, span := c.inst.startSpan(ctx, "DeleteMany", len(ks))
+	defer span.End()
+
+	start := time.Now()
+	var n int
+	if dm, ok := c.AnyCacher.(deleteManyer); ok {
+		n = dm.DeleteMany(ks)
+	} else {
+		for _, k := range ks {
+			c.AnyCacher.Delete(k)
+		}
+		n = len(ks)
+	}
+	c.inst.record(ctx, "DeleteMany", "", ResultOK, start)
+	return n
+}
+
+// NumericCache decorates a NumericCacher, recording metrics for its operations
+// and spans for its loads and bulk operations.
+type NumericCache[T cache.Numeric] struct {
+	*AnyCache[T]
+	nc cache.NumericCacher[T]
+}
+
+var _ cache.NumericCacher[int] = (*NumericCache[int])(nil)
+
+// NewNumericCache returns a new *NumericCache[T] decorating c.
+func NewNumericCache[T cache.Numeric](c cache.NumericCacher[T], opts Options) (*NumericCache[T], error) {
+	ac, err := NewAnyCache[T](c, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &NumericCache[T]{AnyCache: ac, nc: c}, nil
+}
+
+// Increment increments an item by n.
+func (c *NumericCache[T]) Increment(k string, n T) (T, error) {
+	start := time.Now()
+	x, err := c.nc.Increment(k, n)
+	c.inst.record(context.Background(), "Increment", k, result(err), start)
+	return x, err
+}
+
+// Decrement decrements an item by n.
+func (c *NumericCache[T]) Decrement(k string, n T) (T, error) {
+	start := time.Now()
+	x, err := c.nc.Decrement(k, n)
+	c.inst.record(context.Background(), "Decrement", k, result(err), start)
+	return x, err
+}
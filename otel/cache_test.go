@@ -0,0 +1,192 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"sylr.dev/cache/v3"
+)
+
+func newTestProviders() (*sdkmetric.ManualReader, *sdkmetric.MeterProvider, *tracetest.SpanRecorder, *sdktrace.TracerProvider) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return reader, mp, recorder, tp
+}
+
+func findMetric(rm metricdata.ResourceMetrics, name string) (metricdata.Metrics, bool) {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+	return metricdata.Metrics{}, false
+}
+
+func TestAnyCache(t *testing.T) {
+	reader, mp, recorder, tp := newTestProviders()
+	tc, err := NewAnyCache[string](cache.New[string](cache.DefaultExpiration, 0), Options{
+		Name:           "test",
+		MeterProvider:  mp,
+		TracerProvider: tp,
+		KeyAttributes:  KeyPrefix(":"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	tc.Set("user:1", "foo", cache.DefaultExpiration)
+	tc.Get("user:1")
+	tc.Get("user:2")
+	tc.Get("group:1")
+	if err := tc.Add("user:1", "bar", cache.DefaultExpiration); err == nil {
+		t.Error("Successfully added another user:1")
+	}
+
+	rm := metricdata.ResourceMetrics{}
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	m, ok := findMetric(rm, "cache.operations")
+	if !ok {
+		t.Fatal("cache.operations metric not found")
+	}
+	counts := make(map[string]int64)
+	for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+		op, _ := dp.Attributes.Value(CacheOperationKey)
+		res, _ := dp.Attributes.Value(CacheResultKey)
+		prefix, _ := dp.Attributes.Value(CacheKeyPrefixKey)
+		counts[op.AsString()+"/"+res.AsString()+"/"+prefix.AsString()] += dp.Value
+	}
+	expected := map[string]int64{
+		"Set/ok/user":    1,
+		"Get/hit/user":   1,
+		"Get/miss/user":  1,
+		"Get/miss/group": 1,
+		"Add/error/user": 1,
+	}
+	for k, n := range expected {
+		if counts[k] != n {
+			t.Errorf("cache.operations %s is not %d: %d", k, n, counts[k])
+		}
+	}
+
+	m, ok = findMetric(rm, "cache.hit_ratio")
+	if !ok {
+		t.Fatal("cache.hit_ratio metric not found")
+	}
+	dps := m.Data.(metricdata.Gauge[float64]).DataPoints
+	if len(dps) != 1 || dps[0].Value != 1.0/3 {
+		t.Error("cache.hit_ratio is not 1/3:", dps)
+	}
+
+	if n := len(recorder.Ended()); n != 0 {
+		t.Error("single key operations recorded spans:", n)
+	}
+}
+
+func TestAnyCacheGetManyOrLoad(t *testing.T) {
+	_, mp, recorder, tp := newTestProviders()
+	tc, err := NewAnyCache[int](cache.New[int](cache.DefaultExpiration, 0), Options{
+		MeterProvider:  mp,
+		TracerProvider: tp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	tc.Set("a", 1, cache.DefaultExpiration)
+	_, err = tc.GetManyOrLoad(context.Background(), []string{"a", "b"}, func(ctx context.Context, ks []string) (map[string]int, error) {
+		return nil, errors.New("load failed")
+	})
+	if err == nil {
+		t.Error("GetManyOrLoad did not return the loader error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatal("GetManyOrLoad did not record 2 spans:", len(spans))
+	}
+	load, parent := spans[0], spans[1]
+	if load.Name() != "cache.Load" || parent.Name() != "cache.GetManyOrLoad" {
+		t.Error("unexpected span names:", load.Name(), parent.Name())
+	}
+	if load.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("load span is not a child of the GetManyOrLoad span")
+	}
+	if len(load.Events()) != 1 {
+		t.Error("load span did not record the error")
+	}
+	found := false
+	for _, kv := range load.Attributes() {
+		if kv == CacheKeysKey.Int(1) {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("load span does not have a cache.keys attribute of 1:", load.Attributes())
+	}
+
+	if n := tc.SetMany(map[string]int{"c": 3, "d": 4}, cache.DefaultExpiration); n != 2 {
+		t.Error("SetMany did not return 2:", n)
+	}
+	if m := tc.GetMany([]string{"c", "d"}); len(m) != 2 {
+		t.Error("GetMany returned unexpected items:", m)
+	}
+	if n := len(recorder.Ended()); n != 4 {
+		t.Error("bulk operations did not record spans:", n)
+	}
+
+	ctx, outer := tp.Tracer("test").Start(context.Background(), "parent")
+	if n := tc.DeleteManyContext(ctx, []string{"c", "d", "e"}); n != 2 {
+		t.Error("DeleteManyContext did not return 2:", n)
+	}
+	outer.End()
+	spans = recorder.Ended()
+	if len(spans) != 6 || spans[4].Parent().SpanID() != outer.SpanContext().SpanID() {
+		t.Error("DeleteManyContext span is not a child of the context's span")
+	}
+}
+
+func TestNumericCache(t *testing.T) {
+	_, mp, _, tp := newTestProviders()
+	tc, err := NewNumericCache[int](cache.NewNumeric[int](cache.DefaultExpiration, 0), Options{
+		MeterProvider:  mp,
+		TracerProvider: tp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+
+	tc.Set("foo", 1, cache.DefaultExpiration)
+	if x, err := tc.Increment("foo", 2); err != nil || x != 3 {
+		t.Error("Increment returned unexpected result:", x, err)
+	}
+	if x, err := tc.Decrement("foo", 1); err != nil || x != 2 {
+		t.Error("Decrement returned unexpected result:", x, err)
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	f := KeyPrefix(":")
+	if attrs := f("user:1:profile"); attrs[0] != attribute.String("cache.key_prefix", "user") {
+		t.Error("unexpected prefix:", attrs)
+	}
+	if attrs := f("user"); attrs[0] != attribute.String("cache.key_prefix", "") {
+		t.Error("unexpected prefix:", attrs)
+	}
+}
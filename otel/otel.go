@@ -0,0 +1,184 @@
+// Package otel provides decorators recording OpenTelemetry metrics and spans
+// for sylr.dev/cache/v3 caches.
+package otel
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	otelglobal "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "sylr.dev/cache/v3/otel"
+
+// Attribute keys recorded on metrics and spans.
+const (
+	CacheNameKey      = attribute.Key("cache.name")
+	CacheOperationKey = attribute.Key("cache.operation")
+	CacheResultKey    = attribute.Key("cache.result")
+	CacheKeyPrefixKey = attribute.Key("cache.key_prefix")
+	CacheKeysKey      = attribute.Key("cache.keys")
+)
+
+// Values of the cache.result attribute.
+const (
+	ResultHit   = "hit"
+	ResultMiss  = "miss"
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Options configures the decorators.
+type Options struct {
+	// Name is recorded as the cache.name attribute.
+	Name string
+	// MeterProvider is used to create the instruments. Defaults to the global
+	// MeterProvider.
+	MeterProvider metric.MeterProvider
+	// TracerProvider is used to create spans. Defaults to the global
+	// TracerProvider.
+	TracerProvider trace.TracerProvider
+	// KeyAttributes is an (optional) function returning attributes derived from
+	// the key of single key operations, e.g. KeyPrefix(":"). It must not return
+	// high cardinality attributes such as the key itself.
+	KeyAttributes func(k string) []attribute.KeyValue
+}
+
+// KeyPrefix returns a KeyAttributes function recording the part of the key
+// before the first occurrence of sep as the cache.key_prefix attribute. Keys
+// which do not contain sep are recorded with an empty prefix.
+func KeyPrefix(sep string) func(k string) []attribute.KeyValue {
+	return func(k string) []attribute.KeyValue {
+		prefix := ""
+		if i := strings.Index(k, sep); i >= 0 {
+			prefix = k[:i]
+		}
+		return []attribute.KeyValue{CacheKeyPrefixKey.String(prefix)}
+	}
+}
+
+type instruments struct {
+	name     attribute.KeyValue
+	keyAttrs func(k string) []attribute.KeyValue
+	tracer   trace.Tracer
+
+	operations metric.Int64Counter
+	duration   metric.Float64Histogram
+	hitRatio   metric.Float64ObservableGauge
+	reg        metric.Registration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func newInstruments(opts Options) (*instruments, error) {
+	mp := opts.MeterProvider
+	if mp == nil {
+		mp = otelglobal.GetMeterProvider()
+	}
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = otelglobal.GetTracerProvider()
+	}
+
+	meter := mp.Meter(instrumentationName)
+	i := &instruments{
+		name:     CacheNameKey.String(opts.Name),
+		keyAttrs: opts.KeyAttributes,
+		tracer:   tp.Tracer(instrumentationName),
+	}
+
+	var err error
+	i.operations, err = meter.Int64Counter("cache.operations",
+		metric.WithDescription("Number of cache operations."),
+		metric.WithUnit("{operation}"))
+	if err != nil {
+		return nil, err
+	}
+	i.duration, err = meter.Float64Histogram("cache.operation.duration",
+		metric.WithDescription("Duration of cache operations."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	i.hitRatio, err = meter.Float64ObservableGauge("cache.hit_ratio",
+		metric.WithDescription("Ratio of reads which found an item."),
+		metric.WithUnit("1"))
+	if err != nil {
+		return nil, err
+	}
+	i.reg, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		hits, misses := i.hits.Load(), i.misses.Load()
+		if hits+misses > 0 {
+			o.ObserveFloat64(i.hitRatio, float64(hits)/float64(hits+misses), metric.WithAttributes(i.name))
+		}
+		return nil
+	}, i.hitRatio)
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// record records an operation on key k which started at start. k is ignored if
+// empty.
+func (i *instruments) record(ctx context.Context, op, k, result string, start time.Time) {
+	switch result {
+	case ResultHit:
+		i.hits.Add(1)
+	case ResultMiss:
+		i.misses.Add(1)
+	}
+
+	attrs := []attribute.KeyValue{i.name, CacheOperationKey.String(op), CacheResultKey.String(result)}
+	if k != "" && i.keyAttrs != nil {
+		attrs = append(attrs, i.keyAttrs(k)...)
+	}
+	set := metric.WithAttributeSet(attribute.NewSet(attrs...))
+
+	i.operations.Add(ctx, 1, set)
+	i.duration.Record(ctx, time.Since(start).Seconds(), set)
+}
+
+// recordHits records the hits and misses of a bulk read.
+func (i *instruments) recordHits(hits, misses int) {
+	i.hits.Add(uint64(hits))
+	i.misses.Add(uint64(misses))
+}
+
+func (i *instruments) startSpan(ctx context.Context, op string, keys int) (context.Context, trace.Span) {
+	return i.tracer.Start(ctx, "cache."+op, trace.WithAttributes(
+		i.name,
+		CacheOperationKey.String(op),
+		CacheKeysKey.Int(keys),
+	))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultOK
+}
+
+func found(ok bool) string {
+	if ok {
+		return ResultHit
+	}
+	return ResultMiss
+}
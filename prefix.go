@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// EnableKeyIndex enables a radix tree index of the keys of the cache, so that
+// the prefix and pattern operations (KeysByPrefix, DeleteMatching...) only
+// visit the matching keys instead of scanning the whole cache. The index costs
+// memory and makes adding and deleting keys more expensive, so it is disabled
+// by default.
+func (c *anyCache[T]) EnableKeyIndex() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index != nil {
+		return
+	}
+	c.index = newKeyIndex()
+	for k := range c.items {
+		c.index.insert(k)
+	}
+}
+
+// matchGlob reports whether s matches the pattern, in which '*' matches any
+// sequence of characters, '?' matches any single character, i.e. a UTF-8
+// encoded rune or a byte of an invalid encoding, and '\' escapes the next
+// character.
+func matchGlob(pattern, s string) bool {
+	// Position to backtrack to after the last '*': in the pattern and in s.
+	starP, starS := -1, 0
+	p, i := 0, 0
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starP, starS = p, i
+				p++
+				continue
+			case '?':
+				_, n := utf8.DecodeRuneInString(s[i:])
+				p++
+				i += n
+				continue
+			case '\\':
+				if p+1 == len(pattern) {
+					// A trailing '\' is literal.
+					if s[i] == '\\' {
+						p++
+						i++
+						continue
+					}
+				} else if pattern[p+1] == s[i] {
+					p += 2
+					i++
+					continue
+				}
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+		if starP < 0 {
+			return false
+		}
+		// Let the last '*' match one more character.
+		_, n := utf8.DecodeRuneInString(s[starS:])
+		starS += n
+		p, i = starP+1, starS
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// globPrefix returns the literal prefix of a pattern, before its first
+// wildcard.
+func globPrefix(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?':
+			return b.String()
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+		}
+		b.WriteByte(pattern[i])
+	}
+	return b.String()
+}
+
+// walkKeys calls f with the keys starting with prefix, expired ones included,
+// until it returns false. It uses the key index if it is enabled. The cache
+// must be locked, for reading at least.
+func (c *anyCache[T]) walkKeys(prefix string, f func(k string) bool) {
+	if c.index != nil {
+		c.index.walkPrefix(prefix, f)
+		return
+	}
+	for k := range c.items {
+		if strings.HasPrefix(k, prefix) && !f(k) {
+			return
+		}
+	}
+}
+
+// matchingKeys returns the keys of the unexpired items starting with prefix
+// for which match returns true, if not nil. If sorted is true, the keys are
+// sorted.
+func (c *anyCache[T]) matchingKeys(prefix string, match func(k string) bool, sorted bool) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var keys []string
+	now := time.Now().UnixNano()
+	c.walkKeys(prefix, func(k string) bool {
+		if match != nil && !match(k) {
+			return true
+		}
+		// "Inlining" of Expired
+		if v := c.items[k]; v.Expiration > 0 && now > v.Expiration {
+			return true
+		}
+		keys = append(keys, k)
+		return true
+	})
+	// The index walks keys in lexicographic order.
+	if sorted && c.index == nil {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// deleteMatching deletes the items starting with prefix for which match
+// returns true, if not nil, and returns the number of items deleted.
+func (c *anyCache[T]) deleteMatching(prefix string, match func(k string) bool) int {
+	return c.deleteKeysFunc(func() []string {
+		var ks []string
+		c.walkKeys(prefix, func(k string) bool {
+			if match == nil || match(k) {
+				ks = append(ks, k)
+			}
+			return true
+		})
+		return ks
+	})
+}
+
+// KeysByPrefix returns the sorted keys of the unexpired items starting with
+// prefix.
+func (c *anyCache[T]) KeysByPrefix(prefix string) []string {
+	return c.matchingKeys(prefix, nil, true)
+}
+
+// CountByPrefix returns the number of unexpired items whose key starts with
+// prefix.
+func (c *anyCache[T]) CountByPrefix(prefix string) int {
+	return len(c.matchingKeys(prefix, nil, false))
+}
+
+// DeleteByPrefix deletes the items whose key starts with prefix under a single
+// lock and returns the number of items deleted. Expired items are handled as
+// in DeleteMany.
+func (c *anyCache[T]) DeleteByPrefix(prefix string) int {
+	return c.deleteMatching(prefix, nil)
+}
+
+// KeysMatching returns the sorted keys of the unexpired items matching the
+// pattern, in which '*' matches any sequence of characters, '?' matches any
+// single character, i.e. rune, and '\' escapes the next character, e.g.
+// "user:*:profile".
+// With the key index enabled, only the keys starting with the literal prefix of
+// the pattern are visited.
+func (c *anyCache[T]) KeysMatching(pattern string) []string {
+	return c.matchingKeys(globPrefix(pattern), func(k string) bool {
+		return matchGlob(pattern, k)
+	}, true)
+}
+
+// CountMatching returns the number of unexpired items whose key matches the
+// pattern, see KeysMatching.
+func (c *anyCache[T]) CountMatching(pattern string) int {
+	return len(c.matchingKeys(globPrefix(pattern), func(k string) bool {
+		return matchGlob(pattern, k)
+	}, false))
+}
+
+// DeleteMatching deletes the items whose key matches the pattern, see
+// KeysMatching, under a single lock and returns the number of items deleted.
+// Expired items are handled as in DeleteMany.
+func (c *anyCache[T]) DeleteMatching(pattern string) int {
+	return c.deleteMatching(globPrefix(pattern), func(k string) bool {
+		return matchGlob(pattern, k)
+	})
+}
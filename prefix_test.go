@@ -0,0 +1,215 @@
+package cache
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		match   bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "user:1:profile", true},
+		{"user:*:profile", "user:1:profile", true},
+		{"user:*:profile", "user:123:profile", true},
+		{"user:*:profile", "user:1:profile:old", false},
+		{"user:*:profile", "user::profile", true},
+		{"user:*", "user:1:profile", true},
+		{"user:?:profile", "user:1:profile", true},
+		{"user:?:profile", "user:12:profile", false},
+		{"*:profile", "user:1:profile", true},
+		{"a*b*c", "aXbYbZc", true},
+		{"a*b*c", "aXbYbZ", false},
+		{`user\*`, "user*", true},
+		{`user\*`, "user1", false},
+		{`a\?`, "a?", true},
+		{`a\`, `a\`, true},
+		{"?", "é", true},
+		{"??", "é", false},
+		{"user:?:profile", "user:日:profile", true},
+		{"*é?", "aéé本", true},
+		{"\xff?", "\xff", false},
+		{"?", "\xff", true},
+	}
+	for _, tt := range tests {
+		if match := matchGlob(tt.pattern, tt.s); match != tt.match {
+			t.Errorf("matchGlob(%q, %q) = %t, want %t", tt.pattern, tt.s, match, tt.match)
+		}
+	}
+
+	if p := globPrefix(`user:\*:*:profile`); p != "user:*:" {
+		t.Error("unexpected literal prefix:", p)
+	}
+}
+
+func TestKeyIndex(t *testing.T) {
+	idx := newKeyIndex()
+	keys := map[string]bool{}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		// Short keys over a small alphabet share many prefixes.
+		b := make([]byte, 1+r.Intn(6))
+		for j := range b {
+			b[j] = "abc:"[r.Intn(4)]
+		}
+		k := string(b)
+		if r.Intn(3) == 0 {
+			idx.delete(k)
+			delete(keys, k)
+		} else {
+			idx.insert(k)
+			keys[k] = true
+		}
+	}
+
+	for _, prefix := range []string{"", "a", "ab", "a:c", "c:::", "zz"} {
+		var want []string
+		for k := range keys {
+			if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+				want = append(want, k)
+			}
+		}
+		sort.Strings(want)
+		var got []string
+		idx.walkPrefix(prefix, func(k string) bool {
+			got = append(got, k)
+			return true
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("prefix %q: got %d keys, want %d", prefix, len(got), len(want))
+		}
+	}
+
+	for k := range keys {
+		idx.delete(k)
+	}
+	if len(idx.root.children) != 0 {
+		t.Error("index was not emptied")
+	}
+}
+
+func TestKeyIndexWalkAfter(t *testing.T) {
+	idx := newKeyIndex()
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "ba", "c"}
+	for _, k := range keys {
+		idx.insert(k)
+	}
+	for _, after := range []string{"", "a", "aa", "abc", "abcd", "az", "c", "d"} {
+		var want []string
+		for _, k := range keys {
+			if k > after {
+				want = append(want, k)
+			}
+		}
+		var got []string
+		idx.walkAfter(after, func(k string) bool {
+			got = append(got, k)
+			return true
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("walkAfter(%q) = %q, want %q", after, got, want)
+		}
+	}
+}
+
+func testPrefixOperations(t *testing.T, index bool) {
+	tc := New[int](DefaultExpiration, 0)
+	if index {
+		tc.EnableKeyIndex()
+	}
+	for i := 0; i < 5; i++ {
+		tc.Set("user:"+strconv.Itoa(i)+":profile", i, DefaultExpiration)
+		tc.Set("user:"+strconv.Itoa(i)+":settings", i, DefaultExpiration)
+	}
+	tc.Set("user:1:expired", 1, time.Nanosecond)
+	tc.Set("users", 0, DefaultExpiration)
+	tc.Set("group:1:profile", 1, DefaultExpiration)
+	<-time.After(time.Millisecond)
+
+	if keys := tc.KeysByPrefix("user:1:"); !reflect.DeepEqual(keys, []string{"user:1:profile", "user:1:settings"}) {
+		t.Error("unexpected keys by prefix:", keys)
+	}
+	if n := tc.CountByPrefix("user"); n != 11 {
+		t.Error("unexpected count by prefix:", n)
+	}
+	if keys := tc.KeysMatching("*:1:profile"); !reflect.DeepEqual(keys, []string{"group:1:profile", "user:1:profile"}) {
+		t.Error("unexpected matching keys:", keys)
+	}
+	if n := tc.CountMatching("user:*:profile"); n != 5 {
+		t.Error("unexpected matching count:", n)
+	}
+
+	var evicted []string
+	tc.OnEvictedWithReason(func(k string, v int, reason EvictionReason) {
+		evicted = append(evicted, k+":"+reason.String())
+	})
+	// Expired items are deleted too, but as expired and without being counted.
+	if n := tc.DeleteByPrefix("user:1:"); n != 2 || len(evicted) != 3 {
+		t.Error("unexpected number of deleted items:", n, evicted)
+	}
+	sort.Strings(evicted)
+	if evicted[0] != "user:1:expired:expired" || evicted[1] != "user:1:profile:deleted" {
+		t.Error("unexpected eviction reasons:", evicted)
+	}
+	if n := tc.DeleteMatching("user:?:settings"); n != 4 {
+		t.Error("unexpected number of deleted items:", n)
+	}
+	if keys := tc.KeysByPrefix(""); len(keys) != 6 {
+		t.Error("unexpected remaining keys:", keys)
+	}
+
+	tc.Flush()
+	tc.Set("user:1:profile", 1, DefaultExpiration)
+	if n := tc.CountByPrefix("user:"); n != 1 {
+		t.Error("unexpected count after Flush:", n)
+	}
+}
+
+func TestPrefixOperations(t *testing.T) {
+	testPrefixOperations(t, false)
+}
+
+func TestPrefixOperationsKeyIndex(t *testing.T) {
+	testPrefixOperations(t, true)
+}
+
+func TestKeyIndexSetMissing(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.EnableKeyIndex()
+	tc.SetMissing("foo", DefaultExpiration)
+	if n := tc.CountByPrefix("f"); n != 0 {
+		t.Error("negative entry was counted:", n)
+	}
+}
+
+func benchmarkKeysByPrefix(b *testing.B, index bool) {
+	tc := New[int](DefaultExpiration, 0)
+	if index {
+		tc.EnableKeyIndex()
+	}
+	for i := 0; i < 100000; i++ {
+		tc.Set("user:"+strconv.Itoa(i)+":profile", i, DefaultExpiration)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tc.KeysByPrefix("user:4242")
+	}
+}
+
+func BenchmarkKeysByPrefix(b *testing.B) {
+	benchmarkKeysByPrefix(b, false)
+}
+
+func BenchmarkKeysByPrefixKeyIndex(b *testing.B) {
+	benchmarkKeysByPrefix(b, true)
+}
@@ -0,0 +1,148 @@
+// Package prometheus provides a prometheus.Collector exposing the statistics of
+// sylr.dev/cache/v3 caches.
+package prometheus
+
+import (
+	"fmt"
+	"sync"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"sylr.dev/cache/v3"
+)
+
+// StatsCache is implemented by the caches a Collector can expose, e.g.
+// *cache.AnyCache[T] and *cache.NumericCache[T].
+type StatsCache interface {
+	ItemCount() int
+	Stats() cache.Stats
+}
+
+// Collector is a prometheus.Collector exposing the statistics of one or many
+// named caches. All metrics have a "cache" label holding the name the cache was
+// registered with.
+type Collector struct {
+	mu     sync.RWMutex
+	caches map[string]StatsCache
+
+	items         *prom.Desc
+	hits          *prom.Desc
+	misses        *prom.Desc
+	negativeHits  *prom.Desc
+	sets          *prom.Desc
+	evictions     *prom.Desc
+	loads         *prom.Desc
+	loadErrors    *prom.Desc
+	loadDuration  *prom.Desc
+	sweepDuration *prom.Desc
+}
+
+var _ prom.Collector = (*Collector)(nil)
+
+// NewCollector returns a new *Collector. Metric names are prefixed with
+// namespace, which defaults to "cache" if empty.
+func NewCollector(namespace string) *Collector {
+	if namespace == "" {
+		namespace = "cache"
+	}
+	labels := []string{"cache"}
+	desc := func(name, help string, extraLabels ...string) *prom.Desc {
+		return prom.NewDesc(prom.BuildFQName(namespace, "", name), help, append(labels, extraLabels...), nil)
+	}
+	return &Collector{
+		caches:        make(map[string]StatsCache),
+		items:         desc("items", "Number of items in the cache, including expired items not yet cleaned up."),
+		hits:          desc("hits_total", "Number of reads which found an item."),
+		misses:        desc("misses_total", "Number of reads which did not find an item."),
+		negativeHits:  desc("negative_hits_total", "Number of reads which found a negative entry."),
+		sets:          desc("sets_total", "Number of items set."),
+		evictions:     desc("evictions_total", "Number of items removed from the cache by reason.", "reason"),
+		loads:         desc("loads_total", "Number of calls made to loaders."),
+		loadErrors:    desc("load_errors_total", "Number of calls made to loaders which failed."),
+		loadDuration:  desc("load_duration_seconds", "Time spent in loaders."),
+		sweepDuration: desc("janitor_sweep_duration_seconds", "Time spent removing expired items."),
+	}
+}
+
+// statsEnabler is implemented by the caches whose statistics are disabled by
+// default, e.g. *cache.AnyCache[T].
+type statsEnabler interface {
+	EnableStats()
+}
+
+// Register adds c to the caches exposed by the collector under name and enables
+// its statistics if it has an EnableStats method. It returns an error if a
+// cache is already registered under that name.
+func (col *Collector) Register(name string, c StatsCache) error {
+	col.mu.Lock()
+	defer col.mu.Unlock()
+
+	if _, found := col.caches[name]; found {
+		return fmt.Errorf("cache %s already registered", name)
+	}
+	if s, ok := c.(statsEnabler); ok {
+		s.EnableStats()
+	}
+	col.caches[name] = c
+	return nil
+}
+
+// MustRegister is like Register but panics if name is already registered.
+func (col *Collector) MustRegister(name string, c StatsCache) {
+	if err := col.Register(name, c); err != nil {
+		panic(err)
+	}
+}
+
+// Unregister removes the cache registered under name from the collector.
+func (col *Collector) Unregister(name string) {
+	col.mu.Lock()
+	delete(col.caches, name)
+	col.mu.Unlock()
+}
+
+// Describe implements prometheus.Collector.
+func (col *Collector) Describe(ch chan<- *prom.Desc) {
+	ch <- col.items
+	ch <- col.hits
+	ch <- col.misses
+	ch <- col.negativeHits
+	ch <- col.sets
+	ch <- col.evictions
+	ch <- col.loads
+	ch <- col.loadErrors
+	ch <- col.loadDuration
+	ch <- col.sweepDuration
+}
+
+// Collect implements prometheus.Collector.
+func (col *Collector) Collect(ch chan<- prom.Metric) {
+	col.mu.RLock()
+	defer col.mu.RUnlock()
+
+	for name, c := range col.caches {
+		st := c.Stats()
+
+		ch <- prom.MustNewConstMetric(col.items, prom.GaugeValue, float64(c.ItemCount()), name)
+		ch <- prom.MustNewConstMetric(col.hits, prom.CounterValue, float64(st.Hits), name)
+		ch <- prom.MustNewConstMetric(col.misses, prom.CounterValue, float64(st.Misses), name)
+		ch <- prom.MustNewConstMetric(col.negativeHits, prom.CounterValue, float64(st.NegativeHits), name)
+		ch <- prom.MustNewConstMetric(col.sets, prom.CounterValue, float64(st.Sets), name)
+		for reason, n := range st.Evictions {
+			ch <- prom.MustNewConstMetric(col.evictions, prom.CounterValue, float64(n), name, reason.String())
+		}
+		ch <- prom.MustNewConstMetric(col.loads, prom.CounterValue, float64(st.Loads), name)
+		ch <- prom.MustNewConstMetric(col.loadErrors, prom.CounterValue, float64(st.LoadErrors), name)
+
+		// Prometheus histogram buckets are cumulative.
+		buckets := make(map[float64]uint64, len(cache.LoadLatencyBuckets))
+		var cumulative uint64
+		for i, upper := range cache.LoadLatencyBuckets {
+			cumulative += st.LoadLatency[i]
+			buckets[upper.Seconds()] = cumulative
+		}
+		ch <- prom.MustNewConstHistogram(col.loadDuration, st.Loads, st.LoadDuration.Seconds(), buckets, name)
+
+		ch <- prom.MustNewConstSummary(col.sweepDuration, st.Sweeps, st.SweepDuration.Seconds(), nil, name)
+	}
+}
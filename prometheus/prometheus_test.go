@@ -0,0 +1,69 @@
+package prometheus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"sylr.dev/cache/v3"
+)
+
+func TestCollector(t *testing.T) {
+	ac := cache.New[string](cache.DefaultExpiration, 0)
+	ac.EnableStats()
+	ac.Set("foo", "bar", cache.DefaultExpiration)
+	ac.Get("foo")
+	ac.Get("baz")
+
+	nc := cache.NewNumeric[int](cache.DefaultExpiration, 0)
+	nc.EnableStats()
+	nc.Set("foo", 1, cache.DefaultExpiration)
+	nc.Delete("foo")
+
+	col := NewCollector("")
+	col.MustRegister("any", ac)
+	col.MustRegister("numeric", nc)
+	if err := col.Register("any", ac); err == nil {
+		t.Error("registering a cache twice did not return an error")
+	}
+
+	expected := `
+# HELP cache_hits_total Number of reads which found an item.
+# TYPE cache_hits_total counter
+cache_hits_total{cache="any"} 1
+cache_hits_total{cache="numeric"} 0
+# HELP cache_items Number of items in the cache, including expired items not yet cleaned up.
+# TYPE cache_items gauge
+cache_items{cache="any"} 1
+cache_items{cache="numeric"} 0
+# HELP cache_misses_total Number of reads which did not find an item.
+# TYPE cache_misses_total counter
+cache_misses_total{cache="any"} 1
+cache_misses_total{cache="numeric"} 0
+`
+	if err := testutil.CollectAndCompare(col, strings.NewReader(expected), "cache_hits_total", "cache_items", "cache_misses_total"); err != nil {
+		t.Error(err)
+	}
+
+	expected = `
+# HELP cache_evictions_total Number of items removed from the cache by reason.
+# TYPE cache_evictions_total counter
+cache_evictions_total{cache="numeric",reason="deleted"} 1
+cache_evictions_total{cache="numeric",reason="dependency"} 0
+cache_evictions_total{cache="numeric",reason="expired"} 0
+cache_evictions_total{cache="numeric",reason="flushed"} 0
+`
+	col.Unregister("any")
+	if err := testutil.CollectAndCompare(col, strings.NewReader(expected), "cache_evictions_total"); err != nil {
+		t.Error(err)
+	}
+
+	if n := testutil.CollectAndCount(col); n != 13 {
+		t.Error("unexpected number of metrics collected:", n)
+	}
+
+	if problems, err := testutil.CollectAndLint(col); err != nil || len(problems) > 0 {
+		t.Error("collector metrics do not pass lint:", problems, err)
+	}
+}
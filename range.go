@@ -0,0 +1,192 @@
+package cache
+
+import (
+	"iter"
+	"time"
+)
+
+// DefaultRangeChunkSize is the number of items RangeChunked and AllChunked read
+// per chunk when given a size lower than 1.
+const DefaultRangeChunkSize = 1024
+
+// Range calls f sequentially for each unexpired item in the cache. If f
+// returns false, Range stops the iteration.
+//
+// Range holds the read lock of the cache during the whole iteration, so it sees
+// a consistent snapshot of the cache without copying it, but writers are
+// blocked until it returns. f must not call any method of the cache, not even
+// Get: a read lock cannot be taken again while a writer is waiting, so doing so
+// deadlocks. See RangeChunked to call the cache from f or to iterate over large
+// caches.
+func (c *anyCache[T]) Range(f func(k string, v T) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now().UnixNano()
+	for k, v := range c.items {
+		// "Inlining" of Expired
+		if v.Expiration > 0 && now > v.Expiration {
+			continue
+		}
+		if !f(k, v.Object) {
+			return
+		}
+	}
+}
+
+// RangeChunked calls f sequentially for each unexpired item in the cache,
+// without holding the lock of the cache during the whole iteration. If f
+// returns false, RangeChunked stops the iteration. If size is lower than 1,
+// DefaultRangeChunkSize is used.
+//
+// RangeChunked reads the items size at a time, releasing the lock of the cache
+// between chunks and while calling f, which can therefore modify the cache. The
+// iteration is weakly consistent: items deleted or expired before their chunk
+// is read are skipped, and the values are the ones items had when their chunk
+// was read.
+//
+// If the key index is enabled, see EnableKeyIndex, each chunk is read by
+// walking the index from the last key read, in lexicographic order, so the read
+// lock is only held for size keys at a time and no copy of the keys is made.
+// Items added during the iteration are visited if their key comes after the
+// last key read.
+//
+// Otherwise, as the items of a map cannot be walked in steps, RangeChunked
+// first copies all the keys of the cache under a single read lock, which scans
+// the whole cache and costs memory in proportion to its number of items. Items
+// added after RangeChunked was called are then not visited.
+func (c *anyCache[T]) RangeChunked(size int, f func(k string, v T) bool) {
+	if size < 1 {
+		size = DefaultRangeChunkSize
+	}
+
+	c.mu.RLock()
+	if c.index != nil {
+		c.mu.RUnlock()
+		c.rangeIndexChunked(size, f)
+		return
+	}
+	keys := make([]string, 0, len(c.items))
+	for k := range c.items {
+		keys = append(keys, k)
+	}
+	c.mu.RUnlock()
+
+	chunk := make([]keyAndValue[T], 0, min(size, len(keys)))
+	for len(keys) > 0 {
+		n := min(size, len(keys))
+		chunk = chunk[:0]
+
+		c.mu.RLock()
+		now := time.Now().UnixNano()
+		for _, k := range keys[:n] {
+			v, found := c.items[k]
+			// "Inlining" of Expired
+			if !found || (v.Expiration > 0 && now > v.Expiration) {
+				continue
+			}
+			chunk = append(chunk, keyAndValue[T]{k, v.Object})
+		}
+		c.mu.RUnlock()
+
+		keys = keys[n:]
+		for _, kv := range chunk {
+			if !f(kv.key, kv.value) {
+				return
+			}
+		}
+	}
+}
+
+// rangeIndexChunked is RangeChunked walking the key index size keys at a time.
+func (c *anyCache[T]) rangeIndexChunked(size int, f func(k string, v T) bool) {
+	var chunk []keyAndValue[T]
+	var last string
+	var now int64
+	for started := false; ; started = true {
+		chunk = chunk[:0]
+		n := 0
+		visit := func(k string) bool {
+			n++
+			last = k
+			v, found := c.items[k]
+			// "Inlining" of Expired
+			if found && !(v.Expiration > 0 && now > v.Expiration) {
+				chunk = append(chunk, keyAndValue[T]{k, v.Object})
+			}
+			return n < size
+		}
+
+		c.mu.RLock()
+		now = time.Now().UnixNano()
+		if started {
+			c.index.walkAfter(last, visit)
+		} else {
+			c.index.walkPrefix("", visit)
+		}
+		c.mu.RUnlock()
+
+		for _, kv := range chunk {
+			if !f(kv.key, kv.value) {
+				return
+			}
+		}
+		if n < size {
+			return
+		}
+	}
+}
+
+// All returns an iterator over the unexpired items in the cache. It is
+// AllChunked with DefaultRangeChunkSize: the lock of the cache is not held
+// while the loop body runs, which can therefore call any method of the cache,
+// and the iteration is weakly consistent. Use Range for a consistent snapshot.
+func (c *anyCache[T]) All() iter.Seq2[string, T] {
+	return c.AllChunked(DefaultRangeChunkSize)
+}
+
+// AllChunked returns an iterator over the unexpired items in the cache, with
+// the same consistency as RangeChunked: the lock of the cache is not held while
+// the loop body runs, which can therefore modify the cache.
+func (c *anyCache[T]) AllChunked(size int) iter.Seq2[string, T] {
+	return func(yield func(string, T) bool) {
+		c.RangeChunked(size, yield)
+	}
+}
+
+// Keys returns the keys of the unexpired items in the cache. They are copied
+// under the read lock of the cache, so they are a consistent snapshot.
+func (c *anyCache[T]) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]string, 0, len(c.items))
+	now := time.Now().UnixNano()
+	for k, v := range c.items {
+		// "Inlining" of Expired
+		if v.Expiration > 0 && now > v.Expiration {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Values returns the values of the unexpired items in the cache, in no
+// particular order. They are copied under the read lock of the cache, so they
+// are a consistent snapshot.
+func (c *anyCache[T]) Values() []T {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	values := make([]T, 0, len(c.items))
+	now := time.Now().UnixNano()
+	for _, v := range c.items {
+		// "Inlining" of Expired
+		if v.Expiration > 0 && now > v.Expiration {
+			continue
+		}
+		values = append(values, v.Object)
+	}
+	return values
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newRangeTestCache() *AnyCache[int] {
+	tc := New[int](DefaultExpiration, 0)
+	for i := 0; i < 10; i++ {
+		tc.Set(strconv.Itoa(i), i, DefaultExpiration)
+	}
+	tc.Set("expired", -1, time.Nanosecond)
+	<-time.After(time.Millisecond)
+	return tc
+}
+
+func TestRange(t *testing.T) {
+	tc := newRangeTestCache()
+
+	sum, n := 0, 0
+	tc.Range(func(k string, v int) bool {
+		if k != strconv.Itoa(v) {
+			t.Error("unexpected item:", k, v)
+		}
+		sum += v
+		n++
+		return true
+	})
+	if n != 10 || sum != 45 {
+		t.Error("Range did not visit all unexpired items:", n, sum)
+	}
+
+	n = 0
+	tc.Range(func(k string, v int) bool {
+		n++
+		return n < 3
+	})
+	if n != 3 {
+		t.Error("Range did not stop:", n)
+	}
+}
+
+func testRangeChunked(t *testing.T, index bool) {
+	tc := newRangeTestCache()
+	if index {
+		tc.EnableKeyIndex()
+	}
+
+	for _, size := range []int{0, 1, 3, 10, 100} {
+		sum, n := 0, 0
+		tc.RangeChunked(size, func(k string, v int) bool {
+			sum += v
+			n++
+			return true
+		})
+		if n != 10 || sum != 45 {
+			t.Errorf("RangeChunked(%d) did not visit all unexpired items: %d, %d", size, n, sum)
+		}
+	}
+
+	// The cache can be modified during the iteration. The new keys come before
+	// the ones being visited in the key index.
+	seen := map[string]bool{}
+	tc.RangeChunked(2, func(k string, v int) bool {
+		seen[k] = true
+		tc.Delete(k)
+		tc.Set("+"+k, v, DefaultExpiration)
+		return true
+	})
+	if len(seen) != 10 {
+		t.Error("RangeChunked did not visit all items:", len(seen))
+	}
+	if n := len(tc.Keys()); n != 10 {
+		t.Error("unexpected number of keys:", n)
+	}
+
+	n := 0
+	tc.RangeChunked(3, func(k string, v int) bool {
+		n++
+		return n < 5
+	})
+	if n != 5 {
+		t.Error("RangeChunked did not stop:", n)
+	}
+}
+
+func TestRangeChunked(t *testing.T) {
+	testRangeChunked(t, false)
+}
+
+func TestRangeChunkedKeyIndex(t *testing.T) {
+	testRangeChunked(t, true)
+}
+
+func TestAll(t *testing.T) {
+	tc := newRangeTestCache()
+
+	sum := 0
+	for _, v := range tc.All() {
+		sum += v
+	}
+	if sum != 45 {
+		t.Error("All did not yield all unexpired items:", sum)
+	}
+
+	// The loop body can call the cache.
+	for k, v := range tc.All() {
+		if x, found := tc.Get(k); !found || x != v {
+			t.Error("Get returned unexpected item:", k, x)
+		}
+		tc.Set(k, v, DefaultExpiration)
+	}
+
+	sum = 0
+	for k, v := range tc.AllChunked(4) {
+		sum += v
+		if v%2 == 0 {
+			tc.Delete(k)
+		}
+	}
+	if sum != 45 || tc.ItemCount() != 6 {
+		t.Error("AllChunked did not yield all unexpired items:", sum, tc.ItemCount())
+	}
+
+	for range tc.All() {
+		break
+	}
+	for range tc.AllChunked(1) {
+		break
+	}
+}
+
+func TestKeysValues(t *testing.T) {
+	tc := newRangeTestCache()
+
+	keys := tc.Keys()
+	sort.Strings(keys)
+	if len(keys) != 10 || keys[0] != "0" || keys[9] != "9" {
+		t.Error("unexpected keys:", keys)
+	}
+
+	values := tc.Values()
+	sort.Ints(values)
+	if len(values) != 10 || values[0] != 0 || values[9] != 9 {
+		t.Error("unexpected values:", values)
+	}
+}
@@ -0,0 +1,131 @@
+// Package ratelimit provides keyed rate limiters backed by the expiring
+// entries of a sylr.dev/cache/v3 NumericCache, so that the state of idle keys
+// is reclaimed by the cache's janitor.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrLimitExceeded is returned by Wait when a request cannot be allowed before
+// the context's deadline, or can never be allowed because it exceeds the
+// limiter's capacity.
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// Limiter limits the rate of requests per key.
+type Limiter interface {
+	// Allow is shorthand for AllowN(k, 1).
+	Allow(k string) bool
+	// AllowN reports whether n requests may happen now for the key, in which
+	// case they are counted.
+	AllowN(k string, n int) bool
+	// Reserve is shorthand for ReserveN(k, 1).
+	Reserve(k string) Reservation
+	// ReserveN reserves n requests for the key at the earliest time they can
+	// happen.
+	ReserveN(k string, n int) Reservation
+	// Wait is shorthand for WaitN(ctx, k, 1).
+	Wait(ctx context.Context, k string) error
+	// WaitN blocks until n requests may happen for the key, or ctx is done.
+	WaitN(ctx context.Context, k string, n int) error
+}
+
+// Reservation is the outcome of a reservation.
+type Reservation struct {
+	// OK reports whether the requests were reserved, in which case they may
+	// happen after Delay. Otherwise, nothing was reserved and Delay is how long
+	// to wait before the requests could be, or a negative duration if they
+	// never can because they exceed the limiter's capacity.
+	OK    bool
+	Delay time.Duration
+}
+
+// never is the Reservation of requests exceeding the capacity of a limiter.
+var never = Reservation{Delay: -1}
+
+// reserveFunc reserves n requests for k if they can happen within maxDelay of
+// now. maxDelay is negative for no limit.
+type reserveFunc func(k string, n int, now time.Time, maxDelay time.Duration) Reservation
+
+// limiter implements Limiter on top of a reserveFunc.
+type limiter struct {
+	reserve reserveFunc
+	now     func() time.Time
+}
+
+func (l *limiter) Allow(k string) bool {
+	return l.AllowN(k, 1)
+}
+
+func (l *limiter) AllowN(k string, n int) bool {
+	if n <= 0 {
+		return true
+	}
+	return l.reserve(k, n, l.now(), 0).OK
+}
+
+func (l *limiter) Reserve(k string) Reservation {
+	return l.ReserveN(k, 1)
+}
+
+func (l *limiter) ReserveN(k string, n int) Reservation {
+	if n <= 0 {
+		return Reservation{OK: true}
+	}
+	return l.reserve(k, n, l.now(), -1)
+}
+
+func (l *limiter) Wait(ctx context.Context, k string) error {
+	return l.WaitN(ctx, k, 1)
+}
+
+// WaitN reserves the requests, then waits for their delay. The requests are
+// not released if ctx is done while waiting.
+func (l *limiter) WaitN(ctx context.Context, k string, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	now := l.now()
+	maxDelay := time.Duration(-1)
+	if deadline, ok := ctx.Deadline(); ok {
+		maxDelay = deadline.Sub(now)
+		if maxDelay < 0 {
+			maxDelay = 0
+		}
+	}
+
+	r := l.reserve(k, n, now, maxDelay)
+	if !r.OK {
+		return ErrLimitExceeded
+	}
+	if r.Delay <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(r.Delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// within reports whether delay does not exceed maxDelay, a negative maxDelay
+// meaning no limit.
+func within(delay, maxDelay time.Duration) bool {
+	return maxDelay < 0 || delay <= maxDelay
+}
+
+// windowKey returns the cache key of the counter of the window w for k.
+func windowKey(k string, w int64) string {
+	return k + "@" + strconv.FormatInt(w, 10)
+}
@@ -0,0 +1,167 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a time aligned on a minute, which can be advanced.
+func fakeClock(l *limiter) *time.Time {
+	now := time.Unix(1_700_000_040, 0)
+	l.now = func() time.Time { return now }
+	return &now
+}
+
+func TestTokenBucket(t *testing.T) {
+	tb := NewTokenBucket(10, time.Second, 5, 0)
+	now := fakeClock(&tb.limiter)
+
+	for i := 0; i < 5; i++ {
+		if !tb.Allow("foo") {
+			t.Fatal("request", i, "was not allowed")
+		}
+	}
+	if tb.Allow("foo") {
+		t.Error("request exceeding the burst was allowed")
+	}
+	if !tb.Allow("bar") {
+		t.Error("keys are not limited independently")
+	}
+	if r := tb.Reserve("foo"); !r.OK || r.Delay != 100*time.Millisecond {
+		t.Error("unexpected reservation:", r)
+	}
+	if r := tb.ReserveN("foo", 6); r.OK || r.Delay >= 0 {
+		t.Error("reserved more than the burst:", r)
+	}
+
+	*now = now.Add(200 * time.Millisecond)
+	if !tb.Allow("foo") {
+		t.Error("refilled token was not allowed")
+	}
+	if tb.Allow("foo") {
+		t.Error("request exceeding the refill was allowed")
+	}
+
+	*now = now.Add(time.Minute)
+	if !tb.AllowN("foo", 5) {
+		t.Error("full bucket did not allow a burst")
+	}
+}
+
+func TestFixedWindow(t *testing.T) {
+	fw := NewFixedWindow(3, time.Minute, 0)
+	now := fakeClock(&fw.limiter)
+
+	if !fw.AllowN("foo", 3) {
+		t.Fatal("requests were not allowed")
+	}
+	if fw.Allow("foo") {
+		t.Error("request exceeding the limit was allowed")
+	}
+	if r := fw.Reserve("foo"); !r.OK || r.Delay != time.Minute {
+		t.Error("unexpected reservation:", r)
+	}
+	if r := fw.ReserveN("foo", 4); r.OK || r.Delay >= 0 {
+		t.Error("reserved more than the limit:", r)
+	}
+
+	*now = now.Add(time.Minute)
+	if !fw.AllowN("foo", 2) {
+		t.Error("requests of a new window were not allowed")
+	}
+	if fw.Allow("foo") {
+		t.Error("reserved request was not counted")
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	sw := NewSlidingWindow(10, time.Minute, 0)
+	now := fakeClock(&sw.limiter)
+
+	if !sw.AllowN("foo", 10) {
+		t.Fatal("requests were not allowed")
+	}
+	if sw.Allow("foo") {
+		t.Error("request exceeding the limit was allowed")
+	}
+
+	// The previous window still fully overlaps the sliding window.
+	*now = now.Add(time.Minute)
+	if sw.Allow("foo") {
+		t.Error("request exceeding the limit was allowed")
+	}
+	if r := sw.Reserve("foo"); !r.OK || r.Delay != 6*time.Second {
+		t.Error("unexpected reservation:", r)
+	}
+
+	// Half of the previous window overlaps the sliding window: 1 + 10/2.
+	*now = now.Add(30 * time.Second)
+	if !sw.AllowN("foo", 4) {
+		t.Error("requests were not allowed")
+	}
+	if sw.Allow("foo") {
+		t.Error("request exceeding the estimated limit was allowed")
+	}
+	if r := sw.ReserveN("foo", 11); r.OK || r.Delay >= 0 {
+		t.Error("reserved more than the limit:", r)
+	}
+}
+
+func TestWait(t *testing.T) {
+	tb := NewTokenBucket(10, time.Second, 1, 0)
+
+	if err := tb.Wait(context.Background(), "foo"); err != nil {
+		t.Error("Wait failed:", err)
+	}
+	start := time.Now()
+	if err := tb.Wait(context.Background(), "foo"); err != nil {
+		t.Error("Wait failed:", err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Error("Wait did not wait:", d)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := tb.Wait(ctx, "foo"); !errors.Is(err, ErrLimitExceeded) {
+		t.Error("Wait did not fail before the deadline:", err)
+	}
+	if err := tb.WaitN(context.Background(), "foo", 2); !errors.Is(err, ErrLimitExceeded) {
+		t.Error("Wait did not fail for requests exceeding the burst:", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := tb.Wait(ctx, "bar"); !errors.Is(err, context.Canceled) {
+		t.Error("Wait did not return the context error:", err)
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	limiters := map[string]Limiter{
+		"TokenBucket":   NewTokenBucket(1, time.Hour, 10, 0),
+		"FixedWindow":   NewFixedWindow(10, time.Hour, 0),
+		"SlidingWindow": NewSlidingWindow(10, time.Hour, 0),
+	}
+	for name, l := range limiters {
+		var allowed int32
+		wg := sync.WaitGroup{}
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if l.Allow("foo") {
+					atomic.AddInt32(&allowed, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		if allowed != 10 {
+			t.Errorf("%s: %d requests allowed", name, allowed)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"time"
+
+	"sylr.dev/cache/v3"
+)
+
+// TokenBucket is a Limiter giving each key a bucket of at most burst tokens,
+// refilled at a constant rate, each request taking a token.
+//
+// It implements the generic cell rate algorithm, which stores a single
+// timestamp per key: the time at which its bucket will be full again. The
+// entry of a key expires at that time, so keys whose bucket is full have no
+// state.
+type TokenBucket struct {
+	limiter
+	c *cache.NumericCache[int64]
+	// interval is the time in nanoseconds it takes to refill a token.
+	interval int64
+	burst    int
+}
+
+var _ Limiter = (*TokenBucket)(nil)
+
+// NewTokenBucket returns a TokenBucket refilling rate tokens every period into
+// buckets of burst tokens. rate must be positive. Idle keys are reclaimed every
+// cleanupInterval, see cache.NewNumeric.
+func NewTokenBucket(rate int, period time.Duration, burst int, cleanupInterval time.Duration) *TokenBucket {
+	interval := int64(period) / int64(rate)
+	if interval < 1 {
+		interval = 1
+	}
+	tb := &TokenBucket{
+		c:        cache.NewNumeric[int64](cache.NoExpiration, cleanupInterval),
+		interval: interval,
+		burst:    burst,
+	}
+	tb.limiter = limiter{reserve: tb.reserve, now: time.Now}
+	return tb
+}
+
+func (tb *TokenBucket) reserve(k string, n int, now time.Time, maxDelay time.Duration) Reservation {
+	if n > tb.burst {
+		return never
+	}
+
+	ts := now.UnixNano()
+	for {
+		// tat is the theoretical arrival time: the time at which the bucket
+		// will be full.
+		tat, version, found := tb.c.GetWithVersion(k)
+		if !found || tat < ts {
+			tat = ts
+		}
+		newTat := tat + int64(n)*tb.interval
+
+		// The requests can happen once the bucket holds n tokens, i.e. once it
+		// is full except for burst-n tokens.
+		delay := time.Duration(newTat - int64(tb.burst)*tb.interval - ts)
+		if delay < 0 {
+			delay = 0
+		}
+		if !within(delay, maxDelay) {
+			return Reservation{Delay: delay}
+		}
+
+		// Retry if the bucket was modified concurrently.
+		if err := tb.c.SetIfVersion(k, newTat, time.Duration(newTat-ts), version); err == nil {
+			return Reservation{OK: true, Delay: delay}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	"sylr.dev/cache/v3"
+)
+
+// FixedWindow is a Limiter allowing at most limit requests per key in each
+// window of time, windows being aligned on multiples of their duration.
+//
+// Each window of a key has its own counter which expires at the end of the
+// window.
+type FixedWindow struct {
+	limiter
+	c      *cache.NumericCache[int64]
+	limit  int64
+	window int64
+}
+
+var _ Limiter = (*FixedWindow)(nil)
+
+// NewFixedWindow returns a FixedWindow allowing limit requests per window.
+// window must be positive. Idle keys are reclaimed every cleanupInterval, see
+// cache.NewNumeric.
+func NewFixedWindow(limit int, window time.Duration, cleanupInterval time.Duration) *FixedWindow {
+	fw := &FixedWindow{
+		c:      cache.NewNumeric[int64](cache.NoExpiration, cleanupInterval),
+		limit:  int64(limit),
+		window: int64(window),
+	}
+	fw.limiter = limiter{reserve: fw.reserve, now: time.Now}
+	return fw
+}
+
+func (fw *FixedWindow) reserve(k string, n int, now time.Time, maxDelay time.Duration) Reservation {
+	if int64(n) > fw.limit {
+		return never
+	}
+
+	ts := now.UnixNano()
+	for w := ts / fw.window; ; w++ {
+		key := windowKey(k, w)
+		if count, _ := fw.c.Get(key); count+int64(n) > fw.limit {
+			continue
+		}
+
+		start := w * fw.window
+		delay := time.Duration(start - ts)
+		if delay < 0 {
+			delay = 0
+		}
+		if !within(delay, maxDelay) {
+			return Reservation{Delay: delay}
+		}
+
+		ttl := time.Duration(start + fw.window - ts)
+		if count := fw.c.IncrementOrSet(key, int64(n), ttl); count > fw.limit {
+			// The window was filled concurrently, check it again.
+			fw.c.Decrement(key, int64(n))
+			w--
+			continue
+		}
+		return Reservation{OK: true, Delay: delay}
+	}
+}
+
+// SlidingWindow is a Limiter allowing at most limit requests per key in any
+// window of time, approximately.
+//
+// It implements the sliding window counter algorithm: requests are counted in
+// fixed windows, and the number of requests in the window ending now is
+// estimated as the count of the current fixed window plus the count of the
+// previous one weighted by the part of it which overlaps the sliding window.
+// The counter of each fixed window expires at the end of the next one.
+type SlidingWindow struct {
+	limiter
+	c      *cache.NumericCache[int64]
+	limit  int64
+	window int64
+}
+
+var _ Limiter = (*SlidingWindow)(nil)
+
+// NewSlidingWindow returns a SlidingWindow allowing limit requests per window.
+// window must be positive. Idle keys are reclaimed every cleanupInterval, see
+// cache.NewNumeric.
+func NewSlidingWindow(limit int, window time.Duration, cleanupInterval time.Duration) *SlidingWindow {
+	sw := &SlidingWindow{
+		c:      cache.NewNumeric[int64](cache.NoExpiration, cleanupInterval),
+		limit:  int64(limit),
+		window: int64(window),
+	}
+	sw.limiter = limiter{reserve: sw.reserve, now: time.Now}
+	return sw
+}
+
+// estimate returns the estimated number of requests in the sliding window
+// ending elapsed nanoseconds after the start of the fixed window whose count
+// is cur, prev being the count of the previous fixed window.
+func (sw *SlidingWindow) estimate(prev, cur, elapsed int64) float64 {
+	return float64(cur) + float64(prev)*(1-float64(elapsed)/float64(sw.window))
+}
+
+// earliest returns the earliest time from ts at which n requests fit in the
+// sliding window, and the fixed window it belongs to.
+func (sw *SlidingWindow) earliest(k string, n, ts int64) (int64, int64) {
+	w := ts / sw.window
+	prev, _ := sw.c.Get(windowKey(k, w-1))
+	for j := w; ; j++ {
+		cur, _ := sw.c.Get(windowKey(k, j))
+		start := j * sw.window
+		if cur+n <= sw.limit {
+			from := start
+			if from < ts {
+				from = ts
+			}
+			if prev == 0 {
+				return from, j
+			}
+			// cur + n + prev*(1-f) <= limit, with f the elapsed fraction of
+			// the fixed window.
+			f := 1 - float64(sw.limit-cur-n)/float64(prev)
+			t := start + int64(math.Ceil(f*float64(sw.window)))
+			if t < from {
+				t = from
+			}
+			if t < start+sw.window {
+				return t, j
+			}
+		}
+		prev = cur
+	}
+}
+
+func (sw *SlidingWindow) reserve(k string, n int, now time.Time, maxDelay time.Duration) Reservation {
+	if int64(n) > sw.limit {
+		return never
+	}
+
+	ts := now.UnixNano()
+	for {
+		t, w := sw.earliest(k, int64(n), ts)
+		delay := time.Duration(t - ts)
+		if !within(delay, maxDelay) {
+			return Reservation{Delay: delay}
+		}
+
+		key := windowKey(k, w)
+		ttl := time.Duration((w+2)*sw.window - ts)
+		count := sw.c.IncrementOrSet(key, int64(n), ttl)
+		prev, _ := sw.c.Get(windowKey(k, w-1))
+		// Tolerate the rounding of the estimate.
+		if sw.estimate(prev, count, t-w*sw.window) <= float64(sw.limit)+1e-9 {
+			return Reservation{OK: true, Delay: delay}
+		}
+		// Requests were counted concurrently, look for another time.
+		sw.c.Decrement(key, int64(n))
+	}
+}
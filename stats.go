@@ -0,0 +1,302 @@
+package cache
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// EvictionReason tells why an item was removed from the cache.
+type EvictionReason int
+
+const (
+	// EvictionDeleted means the item was deleted with Delete, DeleteMany or
+	// DeleteFunc.
+	EvictionDeleted EvictionReason = iota
+	// EvictionExpired means the item expired and was removed by DeleteExpired.
+	EvictionExpired
+	// EvictionFlushed means the item was removed by Flush.
+	EvictionFlushed
+	// EvictionDependency means the item was removed because one of its
+	// dependencies changed, see SetWithDependencies.
+	EvictionDependency
+
+	evictionReasons
+)
+
+// String implements fmt.Stringer.
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionDeleted:
+		return "deleted"
+	case EvictionExpired:
+		return "expired"
+	case EvictionFlushed:
+		return "flushed"
+	case EvictionDependency:
+		return "dependency"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler so that maps keyed by reason,
+// e.g. Stats.Evictions, are marshaled with readable keys.
+func (r EvictionReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *EvictionReason) UnmarshalText(text []byte) error {
+	for reason := EvictionReason(0); reason < evictionReasons; reason++ {
+		if reason.String() == string(text) {
+			*r = reason
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown eviction reason %q", text)
+}
+
+// LoadLatencyBuckets are the upper bounds of the buckets of Stats.LoadLatency.
+var LoadLatencyBuckets = [...]time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Stats holds the statistics of a cache since EnableStats was called or the last
+// call to ResetStats.
+type Stats struct {
+	// Hits is the number of reads which found an item.
+	Hits uint64
+	// Misses is the number of reads which did not find an item.
+	Misses uint64
+	// NegativeHits is the number of reads which found a negative entry (see
+	// SetMissing). They are not counted as misses.
+	NegativeHits uint64
+	// Loads is the number of calls made to loaders.
+	Loads uint64
+	// LoadErrors is the number of calls made to loaders which failed.
+	LoadErrors uint64
+	// LoadDuration is the total time spent in loaders.
+	LoadDuration time.Duration
+	// LoadLatency holds the number of loads per duration bucket. LoadLatency[i]
+	// is the number of loads which took at most LoadLatencyBuckets[i] (and more
+	// than LoadLatencyBuckets[i-1]). The last element counts the loads which
+	// took longer than the last bucket.
+	LoadLatency [len(LoadLatencyBuckets) + 1]uint64
+	// Sets is the number of writes which stored a value: every item set, by
+	// Set, Add, SetMany, IncrementOrSet and the like, and every value of an
+	// existing item modified, by Increment, Multiply, SetMax, GetAndReset,
+	// CompareAndSwap and the like. Failed writes are not counted.
+	Sets uint64
+	// Deletes is the number of items deleted, it is a shorthand for
+	// Evictions[EvictionDeleted].
+	Deletes uint64
+	// Expirations is the number of expired items removed, it is a shorthand for
+	// Evictions[EvictionExpired].
+	Expirations uint64
+	// Evictions is the number of items removed from the cache by reason.
+	Evictions map[EvictionReason]uint64
+	// Sweeps is the number of times DeleteExpired ran, either called by the
+	// janitor or manually.
+	Sweeps uint64
+	// SweepDuration is the total time spent in DeleteExpired.
+	SweepDuration time.Duration
+}
+
+// HitRatio returns the ratio of hits, negative ones included, over all reads.
+func (s Stats) HitRatio() float64 {
+	hits := s.Hits + s.NegativeHits
+	if hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+s.Misses)
+}
+
+// counter is an atomic counter padded to a cache line so that concurrent
+// updates to different counters do not contend.
+type counter struct {
+	n uint64
+	_ [56]byte
+}
+
+func (c *counter) add(n uint64) {
+	atomic.AddUint64(&c.n, n)
+}
+
+func (c *counter) inc() {
+	atomic.AddUint64(&c.n, 1)
+}
+
+func (c *counter) load() uint64 {
+	return atomic.LoadUint64(&c.n)
+}
+
+func (c *counter) reset() {
+	atomic.StoreUint64(&c.n, 0)
+}
+
+// cacheStats must be allocated on its own so that its counters are 64-bit
+// aligned on 32-bit platforms.
+type cacheStats struct {
+	hits         counter
+	misses       counter
+	negativeHits counter
+	loads        counter
+	loadErrors   counter
+	loadNanos    counter
+	loadLatency  [len(LoadLatencyBuckets) + 1]counter
+	sets         counter
+	evictions    [evictionReasons]counter
+	sweeps       counter
+	sweepNanos   counter
+}
+
+func newCacheStats() *cacheStats {
+	return &cacheStats{}
+}
+
+// The recording methods of cacheStats do nothing if s is nil, i.e. if the
+// statistics of the cache are not enabled.
+
+func (s *cacheStats) addHits(n uint64) {
+	if s != nil {
+		s.hits.add(n)
+	}
+}
+
+func (s *cacheStats) addMisses(n uint64) {
+	if s != nil {
+		s.misses.add(n)
+	}
+}
+
+func (s *cacheStats) addNegativeHits(n uint64) {
+	if s != nil {
+		s.negativeHits.add(n)
+	}
+}
+
+func (s *cacheStats) addSets(n uint64) {
+	if s != nil {
+		s.sets.add(n)
+	}
+}
+
+func (s *cacheStats) addEvictions(reason EvictionReason, n uint64) {
+	if s != nil {
+		s.evictions[reason].add(n)
+	}
+}
+
+func (s *cacheStats) recordLoad(d time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	s.loads.inc()
+	if err != nil {
+		s.loadErrors.inc()
+	}
+	s.loadNanos.add(uint64(d))
+	i := 0
+	for i < len(LoadLatencyBuckets) && d > LoadLatencyBuckets[i] {
+		i++
+	}
+	s.loadLatency[i].inc()
+}
+
+func (s *cacheStats) recordSweep(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.sweeps.inc()
+	s.sweepNanos.add(uint64(d))
+}
+
+func (s *cacheStats) snapshot() Stats {
+	if s == nil {
+		s = &cacheStats{}
+	}
+	st := Stats{
+		Hits:          s.hits.load(),
+		Misses:        s.misses.load(),
+		NegativeHits:  s.negativeHits.load(),
+		Loads:         s.loads.load(),
+		LoadErrors:    s.loadErrors.load(),
+		LoadDuration:  time.Duration(s.loadNanos.load()),
+		Sets:          s.sets.load(),
+		Evictions:     make(map[EvictionReason]uint64, evictionReasons),
+		Sweeps:        s.sweeps.load(),
+		SweepDuration: time.Duration(s.sweepNanos.load()),
+	}
+	for i := range s.loadLatency {
+		st.LoadLatency[i] = s.loadLatency[i].load()
+	}
+	for r := EvictionReason(0); r < evictionReasons; r++ {
+		st.Evictions[r] = s.evictions[r].load()
+	}
+	st.Deletes = st.Evictions[EvictionDeleted]
+	st.Expirations = st.Evictions[EvictionExpired]
+	return st
+}
+
+func (s *cacheStats) reset() {
+	if s == nil {
+		return
+	}
+	s.hits.reset()
+	s.misses.reset()
+	s.negativeHits.reset()
+	s.loads.reset()
+	s.loadErrors.reset()
+	s.loadNanos.reset()
+	for i := range s.loadLatency {
+		s.loadLatency[i].reset()
+	}
+	s.sets.reset()
+	for i := range s.evictions {
+		s.evictions[i].reset()
+	}
+	s.sweeps.reset()
+	s.sweepNanos.reset()
+}
+
+// EnableStats enables the statistics of the cache. They are disabled by
+// default because counting reads, even atomically, slows down Get, the more so
+// when many goroutines read concurrently. Calling EnableStats again does
+// nothing.
+func (c *anyCache[T]) EnableStats() {
+	c.stats.CompareAndSwap(nil, newCacheStats())
+}
+
+// Stats returns the statistics of the cache since EnableStats was called or
+// the last call to ResetStats. They are all zero if the statistics of the cache
+// are not enabled.
+func (c *anyCache[T]) Stats() Stats {
+	return c.stats.Load().snapshot()
+}
+
+// ResetStats resets all the statistics of the cache to zero.
+func (c *anyCache[T]) ResetStats() {
+	c.stats.Load().reset()
+}
+
+// recordLoad records a call to a loader which took d and returned err.
+func (c *anyCache[T]) recordLoad(d time.Duration, err error) {
+	c.stats.Load().recordLoad(d, err)
+	if err != nil {
+		c.logLoadError(d, err)
+	}
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	if st := tc.Stats(); st.Sets != 0 || st.Evictions == nil {
+		t.Error("unexpected stats before EnableStats:", st)
+	}
+	tc.Set("foo", 1, DefaultExpiration)
+	if st := tc.Stats(); st.Sets != 0 {
+		t.Error("stats were collected before EnableStats:", st)
+	}
+	tc.EnableStats()
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.SetMany(map[string]int{"bar": 2, "baz": 3}, DefaultExpiration)
+	tc.Set("qux", 4, time.Nanosecond)
+	tc.SetMissing("quux", DefaultExpiration)
+	<-time.After(time.Millisecond)
+
+	tc.Get("foo")
+	tc.Get("bar")
+	tc.Get("qux")
+	tc.Get("nope")
+	tc.Get("quux")
+	tc.GetMany([]string{"foo", "nope"})
+
+	tc.Delete("foo")
+	tc.Delete("nope")
+	tc.DeleteExpired()
+	tc.Flush()
+
+	tc.GetManyOrLoad(context.Background(), []string{"a"}, func(ctx context.Context, ks []string) (map[string]int, error) {
+		return nil, errors.New("load failed")
+	})
+
+	st := tc.Stats()
+	if st.Hits != 3 {
+		t.Error("Hits is not 3:", st.Hits)
+	}
+	if st.Misses != 4 {
+		t.Error("Misses is not 4:", st.Misses)
+	}
+	if st.NegativeHits != 1 {
+		t.Error("NegativeHits is not 1:", st.NegativeHits)
+	}
+	if st.Sets != 4 {
+		t.Error("Sets is not 4:", st.Sets)
+	}
+	if st.Deletes != 1 || st.Evictions[EvictionDeleted] != 1 {
+		t.Error("Deletes is not 1:", st.Deletes)
+	}
+	if st.Expirations != 1 || st.Evictions[EvictionExpired] != 1 {
+		t.Error("Expirations is not 1:", st.Expirations)
+	}
+	if st.Evictions[EvictionFlushed] != 2 {
+		t.Error("Flushed evictions is not 2:", st.Evictions[EvictionFlushed])
+	}
+	if st.Sweeps != 1 {
+		t.Error("Sweeps is not 1:", st.Sweeps)
+	}
+	if st.Loads != 1 || st.LoadErrors != 1 {
+		t.Error("Loads and LoadErrors are not 1:", st.Loads, st.LoadErrors)
+	}
+	var latencies uint64
+	for _, n := range st.LoadLatency {
+		latencies += n
+	}
+	if latencies != 1 {
+		t.Error("LoadLatency does not hold 1 load:", st.LoadLatency)
+	}
+
+	tc.ResetStats()
+	st = tc.Stats()
+	if st.Hits != 0 || st.Misses != 0 || st.Sets != 0 || st.Evictions[EvictionFlushed] != 0 {
+		t.Error("stats were not reset:", st)
+	}
+}
+
+func TestStatsNumericSets(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+	tc.EnableStats()
+	tc.Set("a", 1, DefaultExpiration)
+	tc.Increment("a", 1)
+	tc.IncrementChecked("a", 1)
+	tc.Multiply("a", 2)
+	tc.Divide("a", 0)
+	tc.IncrementOrSet("a", 1, DefaultExpiration)
+	tc.IncrementOrSet("b", 1, DefaultExpiration)
+	tc.SetMax("a", 100, DefaultExpiration)
+	tc.SetMin("a", 1000, DefaultExpiration)
+	tc.GetAndReset("a")
+	tc.Increment("c", 1)
+
+	// The failed Divide, SetMin and Increment are not counted.
+	if st := tc.Stats(); st.Sets != 8 {
+		t.Error("Sets is not 8:", st.Sets)
+	}
+}
+
+func TestStatsHitRatio(t *testing.T) {
+	st := Stats{}
+	if r := st.HitRatio(); r != 0 {
+		t.Error("HitRatio of empty stats is not 0:", r)
+	}
+	st = Stats{Hits: 2, NegativeHits: 1, Misses: 1}
+	if r := st.HitRatio(); r != 0.75 {
+		t.Error("HitRatio is not 0.75:", r)
+	}
+}
+
+func TestEvictionReasonText(t *testing.T) {
+	for r := EvictionReason(0); r < evictionReasons; r++ {
+		text, err := r.MarshalText()
+		if err != nil {
+			t.Error(err)
+		}
+		var r2 EvictionReason
+		if err := r2.UnmarshalText(text); err != nil || r2 != r {
+			t.Error("eviction reason did not round trip:", r, r2, err)
+		}
+	}
+	var r EvictionReason
+	if err := r.UnmarshalText([]byte("nope")); err == nil {
+		t.Error("unknown eviction reason did not return an error")
+	}
+}
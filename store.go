@@ -0,0 +1,630 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Store is a backing store (e.g. a database) a StoreCache reads from and
+// writes to.
+type Store[T any] interface {
+	// Load returns the value stored for k. It must return ErrNotFound if the
+	// key does not exist in the store.
+	Load(ctx context.Context, k string) (T, error)
+	// LoadMany returns the values stored for the given keys. Keys which do not
+	// exist in the store are absent from the returned map.
+	LoadMany(ctx context.Context, ks []string) (map[string]T, error)
+	// Save stores x for k, replacing any existing value.
+	Save(ctx context.Context, k string, x T) error
+	// Delete removes k from the store. Deleting a key which does not exist is
+	// not an error.
+	Delete(ctx context.Context, k string) error
+}
+
+// BatchStore is an optional interface a Store can implement to let write-behind
+// flushes apply a whole batch of writes in one call.
+type BatchStore[T any] interface {
+	Store[T]
+	// SaveMany stores all the given items.
+	SaveMany(ctx context.Context, items map[string]T) error
+	// DeleteMany removes all the given keys.
+	DeleteMany(ctx context.Context, ks []string) error
+}
+
+// WriteMode tells a StoreCache how writes made to the cache are propagated to
+// its Store.
+type WriteMode int
+
+const (
+	// WriteAround only writes to the cache, the store is left untouched.
+	WriteAround WriteMode = iota
+	// WriteThrough synchronously writes to the store before returning.
+	WriteThrough
+	// WriteBehind queues writes which are asynchronously flushed to the store
+	// in batches.
+	WriteBehind
+)
+
+// StoreOptions configures a StoreCache.
+type StoreOptions struct {
+	// ReadThrough loads items missing from the cache from the store.
+	ReadThrough bool
+	// WriteMode sets how writes are propagated to the store.
+	WriteMode WriteMode
+	// BatchSize is the maximum number of queued writes sent to the store in
+	// one batch. When that many writes are queued a flush is triggered without
+	// waiting for FlushInterval. Defaults to 100.
+	BatchSize int
+	// FlushInterval is the maximum time a queued write waits before being
+	// flushed. Defaults to 1 second.
+	FlushInterval time.Duration
+	// MaxRetries is the number of times a failed write is retried before being
+	// dropped. Defaults to 3.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry of a failed write. It is
+	// doubled on every subsequent retry. Defaults to 100 milliseconds.
+	RetryBackoff time.Duration
+	// OnError is an (optional) function called with the key and the error when
+	// a write to the store fails and will not be retried, or when a read-through
+	// load fails in a method which does not return errors.
+	OnError func(k string, err error)
+}
+
+func (o *StoreOptions) setDefaults() {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+	if o.FlushInterval <= 0 {
+		o.FlushInterval = time.Second
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	} else if o.MaxRetries == 0 {
+		o.MaxRetries = 3
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 100 * time.Millisecond
+	}
+}
+
+// StoreCache layers a Store under an AnyCacher. Depending on its options,
+// cache misses are loaded from the store, and writes are propagated to the
+// store either synchronously or in the background.
+//
+// Methods which are not overridden (Items, Flush, DeleteExpired...) only
+// operate on the cache.
+type StoreCache[T any] struct {
+	AnyCacher[T]
+	store Store[T]
+	opts  StoreOptions
+	wb    *writeBehind[T]
+	// nc is set if the cache supports negative entries.
+	nc negativeCacher[T]
+	// lr is set if the cache keeps statistics about loads.
+	lr loadRecorder
+}
+
+// loadRecorder is implemented by caches keeping statistics about loads.
+type loadRecorder interface {
+	recordLoad(d time.Duration, err error)
+}
+
+// negativeCacher is implemented by caches supporting negative entries.
+type negativeCacher[T any] interface {
+	Lookup(k string) (T, LookupResult)
+	cacheLoadedMissing(ks []string, loaded map[string]T)
+}
+
+// NewStoreCache returns a *StoreCache[T] backed by s. If opts.WriteMode is
+// WriteBehind, a goroutine flushing queued writes is started and Close() must
+// be called to stop it.
+func NewStoreCache[T any](c AnyCacher[T], s Store[T], opts StoreOptions) *StoreCache[T] {
+	opts.setDefaults()
+	sc := &StoreCache[T]{
+		AnyCacher: c,
+		store:     s,
+		opts:      opts,
+	}
+	sc.nc, _ = c.(negativeCacher[T])
+	sc.lr, _ = c.(loadRecorder)
+	if opts.WriteMode == WriteBehind {
+		sc.wb = newWriteBehind(s, opts)
+		go sc.wb.run()
+	}
+	return sc
+}
+
+// GetContext gets an item from the cache. If the item is not cached and the
+// cache reads through, it is loaded from the store and cached with the default
+// expiration. Returns ErrNotFound if the item could not be found.
+//
+// If the cache supports negative entries, keys cached as missing are not
+// loaded, and keys the store does not find are cached as missing if a missing
+// expiration has been set (see AnyCache.SetMissingExpiration).
+func (c *StoreCache[T]) GetContext(ctx context.Context, k string) (T, error) {
+	if c.nc != nil {
+		x, r := c.nc.Lookup(k)
+		switch r {
+		case Hit:
+			return x, nil
+		case NegativeHit:
+			return x, keyError(k, ErrNotFound)
+		}
+	} else if x, found := c.AnyCacher.Get(k); found {
+		return x, nil
+	}
+	if !c.opts.ReadThrough {
+		var ret T
+		return ret, keyError(k, ErrNotFound)
+	}
+	if op, found := c.queued(k); found {
+		// The store is not up to date yet.
+		if op.delete {
+			var ret T
+			return ret, keyError(k, ErrNotFound)
+		}
+		c.AnyCacher.Set(k, op.value, DefaultExpiration)
+		return op.value, nil
+	}
+	start := time.Now()
+	x, err := c.store.Load(ctx, k)
+	c.recordLoad(time.Since(start), err)
+	if err != nil {
+		if c.nc != nil && errors.Is(err, ErrNotFound) {
+			c.nc.cacheLoadedMissing([]string{k}, nil)
+		}
+		var ret T
+		return ret, err
+	}
+	c.AnyCacher.Set(k, x, DefaultExpiration)
+	return x, nil
+}
+
+// GetManyContext gets the given keys from the cache. If the cache reads through,
+// all the missing keys are loaded from the store in a single LoadMany call and
+// cached with the default expiration. Keys which could not be found are absent
+// from the returned map. Negative entries are handled as in GetContext.
+func (c *StoreCache[T]) GetManyContext(ctx context.Context, ks []string) (map[string]T, error) {
+	m := make(map[string]T, len(ks))
+	var missing []string
+	for _, k := range ks {
+		if c.nc != nil {
+			x, r := c.nc.Lookup(k)
+			switch r {
+			case Hit:
+				m[k] = x
+			case Miss:
+				missing = append(missing, k)
+			}
+		} else if x, found := c.AnyCacher.Get(k); found {
+			m[k] = x
+		} else {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) == 0 || !c.opts.ReadThrough {
+		return m, nil
+	}
+	if c.wb != nil {
+		// The store is not up to date yet for the keys with queued writes.
+		var unqueued []string
+		for _, k := range missing {
+			op, found := c.wb.lookup(k)
+			switch {
+			case !found:
+				unqueued = append(unqueued, k)
+			case !op.delete:
+				c.AnyCacher.Set(k, op.value, DefaultExpiration)
+				m[k] = op.value
+			}
+		}
+		if missing = unqueued; len(missing) == 0 {
+			return m, nil
+		}
+	}
+	start := time.Now()
+	loaded, err := c.store.LoadMany(ctx, missing)
+	c.recordLoad(time.Since(start), err)
+	if err != nil {
+		return m, err
+	}
+	for k, x := range loaded {
+		c.AnyCacher.Set(k, x, DefaultExpiration)
+		m[k] = x
+	}
+	if c.nc != nil {
+		c.nc.cacheLoadedMissing(missing, loaded)
+	}
+	return m, nil
+}
+
+// Get gets an item from the cache, reading through to the store if the cache
+// is configured to. Load errors other than ErrNotFound are reported to
+// OnError.
+func (c *StoreCache[T]) Get(k string) (T, bool) {
+	x, err := c.GetContext(context.Background(), k)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			c.reportError(k, err)
+		}
+		return x, false
+	}
+	return x, true
+}
+
+// GetWithExpiration returns an item and its expiration time from the cache,
+// reading through to the store if the cache is configured to.
+func (c *StoreCache[T]) GetWithExpiration(k string) (T, time.Time, bool) {
+	if x, e, found := c.AnyCacher.GetWithExpiration(k); found {
+		return x, e, true
+	}
+	if _, found := c.Get(k); !found {
+		var ret T
+		return ret, time.Time{}, false
+	}
+	return c.AnyCacher.GetWithExpiration(k)
+}
+
+// SetContext adds an item to the cache, replacing any existing item, and
+// propagates the write to the store according to the cache's WriteMode. The
+// write is propagated first and the cache is left untouched if it fails: with
+// WriteThrough, the error returned by the store is returned.
+func (c *StoreCache[T]) SetContext(ctx context.Context, k string, x T, d time.Duration) error {
+	if err := c.save(ctx, k, x); err != nil {
+		return err
+	}
+	c.AnyCacher.Set(k, x, d)
+	return nil
+}
+
+// Set adds an item to the cache, replacing any existing item, and propagates
+// the write to the store. Store errors are reported to OnError.
+func (c *StoreCache[T]) Set(k string, x T, d time.Duration) {
+	if err := c.SetContext(context.Background(), k, x, d); err != nil {
+		c.reportError(k, err)
+	}
+}
+
+// SetDefault adds an item to the cache, replacing any existing item, using the
+// default expiration, and propagates the write to the store.
+func (c *StoreCache[T]) SetDefault(k string, x T) {
+	c.Set(k, x, DefaultExpiration)
+}
+
+// Add an item to the cache only if an item doesn't already exist for the given
+// key, or if the existing item has expired. Returns an error otherwise. The
+// write is propagated to the store only if the item was added, and the item is
+// removed from the cache if the propagation fails.
+func (c *StoreCache[T]) Add(k string, x T, d time.Duration) error {
+	if err := c.AnyCacher.Add(k, x, d); err != nil {
+		return err
+	}
+	return c.saveOrEvict(context.Background(), k, x)
+}
+
+// Replace replaces a new value for the cache key only if it already exists, and
+// the existing item hasn't expired. Returns an error otherwise. The write is
+// propagated to the store only if the item was replaced, and the item is
+// removed from the cache if the propagation fails so that it is not served
+// with a value the store does not have.
+func (c *StoreCache[T]) Replace(k string, x T, d time.Duration) error {
+	if err := c.AnyCacher.Replace(k, x, d); err != nil {
+		return err
+	}
+	return c.saveOrEvict(context.Background(), k, x)
+}
+
+// DeleteContext deletes an item from the cache and propagates the deletion to
+// the store according to the cache's WriteMode.
+func (c *StoreCache[T]) DeleteContext(ctx context.Context, k string) error {
+	c.AnyCacher.Delete(k)
+	switch c.opts.WriteMode {
+	case WriteThrough:
+		return c.store.Delete(ctx, k)
+	case WriteBehind:
+		return c.wb.enqueue(k, writeOp[T]{delete: true})
+	}
+	return nil
+}
+
+// Delete deletes an item from the cache and propagates the deletion to the
+// store. Store errors are reported to OnError.
+func (c *StoreCache[T]) Delete(k string) {
+	if err := c.DeleteContext(context.Background(), k); err != nil {
+		c.reportError(k, err)
+	}
+}
+
+// FlushStore writes all queued write-behind operations to the store and waits for
+// them to complete. Failed writes are retried according to the cache options.
+// It does nothing if the cache is not in WriteBehind mode.
+func (c *StoreCache[T]) FlushStore() {
+	if c.wb != nil {
+		c.wb.flushAll()
+	}
+}
+
+// Close flushes all queued write-behind operations to the store and stops the
+// write-behind goroutine. Writes made after Close return ErrClosed. It is safe
+// to call Close multiple times.
+func (c *StoreCache[T]) Close() error {
+	if c.wb != nil {
+		c.wb.close()
+	}
+	return nil
+}
+
+func (c *StoreCache[T]) save(ctx context.Context, k string, x T) error {
+	switch c.opts.WriteMode {
+	case WriteThrough:
+		return c.store.Save(ctx, k, x)
+	case WriteBehind:
+		return c.wb.enqueue(k, writeOp[T]{value: x})
+	}
+	return nil
+}
+
+// saveOrEvict propagates the write of an item already cached to the store and
+// removes it from the cache if the propagation fails.
+func (c *StoreCache[T]) saveOrEvict(ctx context.Context, k string, x T) error {
+	err := c.save(ctx, k, x)
+	if err != nil {
+		c.AnyCacher.Delete(k)
+	}
+	return err
+}
+
+// queued returns the write-behind operation queued or being written for k, if
+// any.
+func (c *StoreCache[T]) queued(k string) (writeOp[T], bool) {
+	if c.wb == nil {
+		return writeOp[T]{}, false
+	}
+	return c.wb.lookup(k)
+}
+
+// recordLoad records a load in the cache statistics. Not finding an item is
+// not counted as a load error.
+func (c *StoreCache[T]) recordLoad(d time.Duration, err error) {
+	if c.lr == nil {
+		return
+	}
+	if errors.Is(err, ErrNotFound) {
+		err = nil
+	}
+	c.lr.recordLoad(d, err)
+}
+
+func (c *StoreCache[T]) reportError(k string, err error) {
+	if c.opts.OnError != nil {
+		c.opts.OnError(k, err)
+	}
+}
+
+type writeOp[T any] struct {
+	value    T
+	delete   bool
+	attempts int
+	retryAt  time.Time
+}
+
+// writeBehind queues writes, coalescing those made to the same key, and
+// flushes them to the store.
+type writeBehind[T any] struct {
+	store Store[T]
+	opts  StoreOptions
+
+	mu      sync.Mutex
+	pending map[string]writeOp[T]
+	// writing holds the writes being sent to the store, so that reads do not
+	// load values they are about to replace.
+	writing map[string]writeOp[T]
+	closed  bool
+
+	// flushMu serializes flushes so that writes to the same key are applied in
+	// order.
+	flushMu sync.Mutex
+
+	kick chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newWriteBehind[T any](s Store[T], opts StoreOptions) *writeBehind[T] {
+	return &writeBehind[T]{
+		store:   s,
+		opts:    opts,
+		pending: make(map[string]writeOp[T]),
+		writing: make(map[string]writeOp[T]),
+		kick:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+func (w *writeBehind[T]) enqueue(k string, op writeOp[T]) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return keyError(k, ErrClosed)
+	}
+	// A newer write supersedes any queued one, including pending retries.
+	w.pending[k] = op
+	n := len(w.pending)
+	w.mu.Unlock()
+
+	if n >= w.opts.BatchSize {
+		select {
+		case w.kick <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// lookup returns the most recent write queued or being written for k, if any.
+func (w *writeBehind[T]) lookup(k string) (writeOp[T], bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if op, found := w.pending[k]; found {
+		return op, true
+	}
+	op, found := w.writing[k]
+	return op, found
+}
+
+func (w *writeBehind[T]) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush(false)
+		case <-w.kick:
+			w.flush(false)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// flush sends the pending writes to the store. Writes waiting for a retry are
+// skipped unless force is true.
+func (w *writeBehind[T]) flush(force bool) {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+
+	now := time.Now()
+	batch := make(map[string]writeOp[T])
+
+	w.mu.Lock()
+	for k, op := range w.pending {
+		if !force && op.retryAt.After(now) {
+			continue
+		}
+		batch[k] = op
+		w.writing[k] = op
+		delete(w.pending, k)
+		if len(batch) == w.opts.BatchSize {
+			w.mu.Unlock()
+			w.write(batch)
+			batch = make(map[string]writeOp[T])
+			w.mu.Lock()
+		}
+	}
+	w.mu.Unlock()
+
+	if len(batch) > 0 {
+		w.write(batch)
+	}
+}
+
+// flushAll flushes until there is no pending write left, waiting for retries'
+// backoffs to elapse.
+func (w *writeBehind[T]) flushAll() {
+	for {
+		w.flush(false)
+
+		w.mu.Lock()
+		var next time.Time
+		for _, op := range w.pending {
+			if next.IsZero() || op.retryAt.Before(next) {
+				next = op.retryAt
+			}
+		}
+		n := len(w.pending)
+		w.mu.Unlock()
+
+		if n == 0 {
+			return
+		}
+		time.Sleep(time.Until(next))
+	}
+}
+
+func (w *writeBehind[T]) write(batch map[string]writeOp[T]) {
+	ctx := context.Background()
+	errs := make(map[string]error)
+
+	if bs, ok := w.store.(BatchStore[T]); ok {
+		saves := make(map[string]T)
+		var deletes []string
+		for k, op := range batch {
+			if op.delete {
+				deletes = append(deletes, k)
+			} else {
+				saves[k] = op.value
+			}
+		}
+		if len(saves) > 0 {
+			if err := bs.SaveMany(ctx, saves); err != nil {
+				for k := range saves {
+					errs[k] = err
+				}
+			}
+		}
+		if len(deletes) > 0 {
+			if err := bs.DeleteMany(ctx, deletes); err != nil {
+				for _, k := range deletes {
+					errs[k] = err
+				}
+			}
+		}
+	} else {
+		for k, op := range batch {
+			var err error
+			if op.delete {
+				err = w.store.Delete(ctx, k)
+			} else {
+				err = w.store.Save(ctx, k, op.value)
+			}
+			if err != nil {
+				errs[k] = err
+			}
+		}
+	}
+
+	now := time.Now()
+	w.mu.Lock()
+	for k := range batch {
+		delete(w.writing, k)
+	}
+	for k, err := range errs {
+		op := batch[k]
+		if op.attempts >= w.opts.MaxRetries {
+			if w.opts.OnError != nil {
+				// Do not hold the lock while calling user code.
+				w.mu.Unlock()
+				w.opts.OnError(k, err)
+				w.mu.Lock()
+			}
+			continue
+		}
+		if _, found := w.pending[k]; found {
+			// A newer write has been queued in the meantime.
+			continue
+		}
+		op.attempts++
+		op.retryAt = now.Add(w.opts.RetryBackoff << (op.attempts - 1))
+		w.pending[k] = op
+	}
+	w.mu.Unlock()
+}
+
+func (w *writeBehind[T]) close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+	w.flushAll()
+}
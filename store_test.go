@@ -0,0 +1,292 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+type testStore[T any] struct {
+	mu        sync.Mutex
+	items     map[string]T
+	loads     int
+	saves     int
+	deletes   int
+	failSaves int
+}
+
+func newTestStore[T any]() *testStore[T] {
+	return &testStore[T]{items: make(map[string]T)}
+}
+
+func (s *testStore[T]) Load(ctx context.Context, k string) (T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	x, found := s.items[k]
+	if !found {
+		return x, ErrNotFound
+	}
+	return x, nil
+}
+
+func (s *testStore[T]) LoadMany(ctx context.Context, ks []string) (map[string]T, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	m := make(map[string]T)
+	for _, k := range ks {
+		if x, found := s.items[k]; found {
+			m[k] = x
+		}
+	}
+	return m, nil
+}
+
+func (s *testStore[T]) Save(ctx context.Context, k string, x T) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failSaves > 0 {
+		s.failSaves--
+		return errors.New("save failed")
+	}
+	s.saves++
+	s.items[k] = x
+	return nil
+}
+
+func (s *testStore[T]) Delete(ctx context.Context, k string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deletes++
+	delete(s.items, k)
+	return nil
+}
+
+func (s *testStore[T]) get(k string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x, found := s.items[k]
+	return x, found
+}
+
+func TestStoreCacheReadThrough(t *testing.T) {
+	s := newTestStore[string]()
+	s.items["foo"] = "bar"
+	tc := NewStoreCache[string](New[string](DefaultExpiration, 0), s, StoreOptions{ReadThrough: true})
+
+	x, found := tc.Get("foo")
+	if !found || x != "bar" {
+		t.Error("foo was not read through:", x)
+	}
+	x, found = tc.Get("foo")
+	if !found || x != "bar" {
+		t.Error("foo was not cached:", x)
+	}
+	if s.loads != 1 {
+		t.Error("store loads is not 1:", s.loads)
+	}
+	if _, err := tc.GetContext(context.Background(), "baz"); !errors.Is(err, ErrNotFound) {
+		t.Error("baz error is not ErrNotFound:", err)
+	}
+
+	s.items["a"] = "1"
+	s.items["b"] = "2"
+	m, err := tc.GetManyContext(context.Background(), []string{"foo", "a", "b", "c"})
+	if err != nil {
+		t.Error("GetManyContext returned an error:", err)
+	}
+	if len(m) != 3 || m["foo"] != "bar" || m["a"] != "1" || m["b"] != "2" {
+		t.Error("GetManyContext returned unexpected items:", m)
+	}
+	if s.loads != 3 {
+		t.Error("store loads is not 3:", s.loads)
+	}
+}
+
+func TestStoreCacheWriteThrough(t *testing.T) {
+	s := newTestStore[int]()
+	tc := NewStoreCache[int](New[int](DefaultExpiration, 0), s, StoreOptions{WriteMode: WriteThrough})
+
+	tc.Set("foo", 1, DefaultExpiration)
+	if x, found := s.get("foo"); !found || x != 1 {
+		t.Error("foo was not written through:", x)
+	}
+	if err := tc.Add("foo", 2, DefaultExpiration); err == nil {
+		t.Error("Successfully added another foo")
+	}
+	if x, _ := s.get("foo"); x != 1 {
+		t.Error("foo was overwritten in the store by a failed Add:", x)
+	}
+	tc.Delete("foo")
+	if _, found := s.get("foo"); found {
+		t.Error("foo was not deleted from the store")
+	}
+
+	s.failSaves = 1
+	if err := tc.SetContext(context.Background(), "bar", 1, DefaultExpiration); err == nil {
+		t.Error("SetContext did not return the store error")
+	}
+	if _, found := tc.AnyCacher.Get("bar"); found {
+		t.Error("bar was cached although it was not written to the store")
+	}
+
+	s.failSaves = 1
+	if err := tc.Add("baz", 1, DefaultExpiration); err == nil {
+		t.Error("Add did not return the store error")
+	}
+	if _, found := tc.AnyCacher.Get("baz"); found {
+		t.Error("baz was added to the cache although it was not written to the store")
+	}
+
+	tc.Set("qux", 1, DefaultExpiration)
+	s.failSaves = 1
+	if err := tc.Replace("qux", 2, DefaultExpiration); err == nil {
+		t.Error("Replace did not return the store error")
+	}
+	if x, found := tc.AnyCacher.Get("qux"); found {
+		t.Error("qux was kept in the cache although it was not written to the store:", x)
+	}
+	if x, _ := s.get("qux"); x != 1 {
+		t.Error("qux was overwritten in the store by a failed Replace:", x)
+	}
+}
+
+func TestStoreCacheWriteBehind(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	s := newTestStore[int]()
+	tc := NewStoreCache[int](New[int](DefaultExpiration, 0), s, StoreOptions{
+		WriteMode:     WriteBehind,
+		FlushInterval: time.Hour,
+	})
+
+	for i := 0; i < 10; i++ {
+		tc.Set("foo", i, DefaultExpiration)
+	}
+	tc.Set("bar", 1, DefaultExpiration)
+	tc.Delete("bar")
+
+	if _, found := s.get("foo"); found {
+		t.Error("foo was written before being flushed")
+	}
+	if err := tc.Close(); err != nil {
+		t.Error("Close returned an error:", err)
+	}
+	if x, found := s.get("foo"); !found || x != 9 {
+		t.Error("foo was not flushed on Close:", x)
+	}
+	if s.saves != 1 {
+		t.Error("writes to foo were not coalesced; saves:", s.saves)
+	}
+	if s.deletes != 1 {
+		t.Error("bar was not deleted; deletes:", s.deletes)
+	}
+	if err := tc.SetContext(context.Background(), "foo", 1, DefaultExpiration); !errors.Is(err, ErrClosed) {
+		t.Error("Set after Close did not return ErrClosed:", err)
+	}
+}
+
+func TestStoreCacheWriteBehindReadThrough(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	s := newTestStore[int]()
+	s.items["foo"] = 1
+	s.items["bar"] = 1
+	c := New[int](DefaultExpiration, 0)
+	tc := NewStoreCache[int](c, s, StoreOptions{
+		ReadThrough:   true,
+		WriteMode:     WriteBehind,
+		FlushInterval: time.Hour,
+	})
+
+	// Reads must not load values from the store which queued writes replace.
+	tc.Set("foo", 2, DefaultExpiration)
+	tc.Delete("foo")
+	if x, found := tc.Get("foo"); found {
+		t.Error("deleted foo was loaded from the store:", x)
+	}
+	tc.Set("bar", 2, DefaultExpiration)
+	c.Delete("bar")
+	if x, _ := tc.Get("bar"); x != 2 {
+		t.Error("bar was not read from the queued writes:", x)
+	}
+	c.Delete("bar")
+	m, err := tc.GetManyContext(context.Background(), []string{"foo", "bar"})
+	if err != nil || len(m) != 1 || m["bar"] != 2 {
+		t.Error("unexpected items:", m, err)
+	}
+	if s.loads != 0 {
+		t.Error("keys with queued writes were loaded from the store:", s.loads)
+	}
+
+	tc.Close()
+	if _, found := s.get("foo"); found {
+		t.Error("foo was not deleted from the store")
+	}
+	if _, found := c.Get("foo"); found {
+		t.Error("foo is still cached")
+	}
+}
+
+func TestStoreCacheWriteBehindBatchSize(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	s := newTestStore[int]()
+	tc := NewStoreCache[int](New[int](DefaultExpiration, 0), s, StoreOptions{
+		WriteMode:     WriteBehind,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	defer tc.Close()
+
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.Set("bar", 2, DefaultExpiration)
+
+	for i := 0; i < 100; i++ {
+		if _, found := s.get("bar"); found {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("full batch was not flushed")
+}
+
+func TestStoreCacheWriteBehindRetry(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	var mu sync.Mutex
+	var errs []error
+	s := newTestStore[int]()
+	s.failSaves = 3
+	tc := NewStoreCache[int](New[int](DefaultExpiration, 0), s, StoreOptions{
+		WriteMode:     WriteBehind,
+		FlushInterval: time.Hour,
+		MaxRetries:    2,
+		RetryBackoff:  time.Millisecond,
+		OnError: func(k string, err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	})
+
+	tc.Set("foo", 1, DefaultExpiration)
+	tc.FlushStore()
+	if _, found := s.get("foo"); found {
+		t.Error("foo was saved although all attempts failed")
+	}
+	if len(errs) != 1 {
+		t.Error("OnError was not called once:", errs)
+	}
+
+	tc.Set("bar", 1, DefaultExpiration)
+	tc.Close()
+	if _, found := s.get("bar"); !found {
+		t.Error("bar was not saved after being retried")
+	}
+}
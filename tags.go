@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"sort"
+	"time"
+)
+
+// tagIndex maps tags to the keys of the items tagged with them and keys to
+// their tags.
+type tagIndex struct {
+	keys map[string]map[string]struct{}
+	tags map[string][]string
+}
+
+func newTagIndex() *tagIndex {
+	return &tagIndex{
+		keys: make(map[string]map[string]struct{}),
+		tags: make(map[string][]string),
+	}
+}
+
+func (t *tagIndex) tag(k string, tags []string) {
+	for _, tag := range tags {
+		keys := t.keys[tag]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			t.keys[tag] = keys
+		}
+		if _, found := keys[k]; found {
+			continue
+		}
+		keys[k] = struct{}{}
+		t.tags[k] = append(t.tags[k], tag)
+	}
+}
+
+func (t *tagIndex) untag(k string) {
+	for _, tag := range t.tags[k] {
+		keys := t.keys[tag]
+		delete(keys, k)
+		if len(keys) == 0 {
+			delete(t.keys, tag)
+		}
+	}
+	delete(t.tags, k)
+}
+
+// SetWithTags adds an item to the cache like Set, replacing any existing item
+// and its tags, and tags it with the given tags, so that it can be deleted
+// along with all the items sharing one of them with InvalidateTag. The tags of
+// an item are removed when it is deleted, expires or is set again without
+// tags. Modifying an item in place, e.g. with Increment or Update, keeps its
+// tags.
+func (c *anyCache[T]) SetWithTags(k string, x T, d time.Duration, tags ...string) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.set(k, x, d)
+	if len(tags) == 0 {
+		return
+	}
+	if c.tags == nil {
+		c.tags = newTagIndex()
+	}
+	c.tags.tag(k, tags)
+}
+
+// InvalidateTag deletes all the items tagged with tag under a single lock and
+// returns the number of items deleted. Expired items are handled as in
+// DeleteMany: they are deleted, with the reason EvictionExpired, but not
+// counted.
+func (c *anyCache[T]) InvalidateTag(tag string) int {
+	return c.deleteKeysFunc(func() []string {
+		if c.tags == nil {
+			return nil
+		}
+		ks := make([]string, 0, len(c.tags.keys[tag]))
+		for k := range c.tags.keys[tag] {
+			ks = append(ks, k)
+		}
+		return ks
+	})
+}
+
+// Tags returns the sorted tags of an unexpired item, or nil if it has none or
+// was not found.
+func (c *anyCache[T]) Tags(k string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.tags == nil {
+		return nil
+	}
+	if _, found := c.get(k); !found {
+		return nil
+	}
+	tags := c.tags.tags[k]
+	if len(tags) == 0 {
+		return nil
+	}
+	tags = append([]string(nil), tags...)
+	sort.Strings(tags)
+	return tags
+}
+
+// KeysForTag returns the sorted keys of the unexpired items tagged with tag.
+func (c *anyCache[T]) KeysForTag(tag string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.tags == nil {
+		return nil
+	}
+	var keys []string
+	now := time.Now().UnixNano()
+	for k := range c.tags.keys[tag] {
+		// "Inlining" of Expired
+		if v := c.items[k]; v.Expiration > 0 && now > v.Expiration {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
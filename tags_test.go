@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTags(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetWithTags("product:1", 1, DefaultExpiration, "products", "category:a", "products")
+	tc.SetWithTags("product:2", 2, DefaultExpiration, "products", "category:b")
+	tc.SetWithTags("product:3", 3, DefaultExpiration, "products", "category:a")
+	tc.Set("other", 0, DefaultExpiration)
+
+	if tags := tc.Tags("product:1"); !reflect.DeepEqual(tags, []string{"category:a", "products"}) {
+		t.Error("unexpected tags:", tags)
+	}
+	if tags := tc.Tags("other"); tags != nil {
+		t.Error("untagged item has tags:", tags)
+	}
+	if tags := tc.Tags("missing"); tags != nil {
+		t.Error("missing item has tags:", tags)
+	}
+	if keys := tc.KeysForTag("category:a"); !reflect.DeepEqual(keys, []string{"product:1", "product:3"}) {
+		t.Error("unexpected keys for tag:", keys)
+	}
+
+	var evicted []string
+	tc.OnEvicted(func(k string, v int) {
+		evicted = append(evicted, k)
+	})
+	if n := tc.InvalidateTag("category:a"); n != 2 {
+		t.Error("unexpected number of invalidated items:", n)
+	}
+	sort.Strings(evicted)
+	if !reflect.DeepEqual(evicted, []string{"product:1", "product:3"}) {
+		t.Error("unexpected evicted items:", evicted)
+	}
+	if _, found := tc.Get("product:1"); found {
+		t.Error("product:1 was not invalidated")
+	}
+	if keys := tc.KeysForTag("products"); !reflect.DeepEqual(keys, []string{"product:2"}) {
+		t.Error("invalidated items are still tagged:", keys)
+	}
+	if n := tc.InvalidateTag("category:a"); n != 0 {
+		t.Error("tag was invalidated twice:", n)
+	}
+	if n := tc.InvalidateTag("unknown"); n != 0 {
+		t.Error("unknown tag invalidated items:", n)
+	}
+
+	tc.SetWithTags("product:4", 4, time.Nanosecond, "category:b")
+	<-time.After(time.Millisecond)
+	reasons := map[string]EvictionReason{}
+	tc.OnEvictedWithReason(func(k string, v int, reason EvictionReason) {
+		reasons[k] = reason
+	})
+	if n := tc.InvalidateTag("category:b"); n != 1 {
+		t.Error("expired item was counted as invalidated:", n)
+	}
+	if reasons["product:2"] != EvictionDeleted || reasons["product:4"] != EvictionExpired {
+		t.Error("unexpected eviction reasons:", reasons)
+	}
+	tc.SetWithTags("product:2", 2, DefaultExpiration, "products", "category:b")
+	if tc.ItemCount() != 2 {
+		t.Error("unexpected item count:", tc.ItemCount())
+	}
+}
+
+func TestTagsCleanup(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	tc.SetWithTags("a", 1, DefaultExpiration, "t")
+	tc.SetWithTags("b", 2, DefaultExpiration, "t")
+	tc.SetWithTags("c", 3, time.Nanosecond, "t")
+	tc.SetWithTags("d", 4, DefaultExpiration, "t", "u")
+	<-time.After(time.Millisecond)
+
+	if keys := tc.KeysForTag("t"); !reflect.DeepEqual(keys, []string{"a", "b", "d"}) {
+		t.Error("unexpected keys for tag:", keys)
+	}
+
+	// Setting an item again without tags removes its tags, modifying it in
+	// place keeps them.
+	tc.Set("a", 1, DefaultExpiration)
+	tc.Update("b", func(old int, exists bool) (int, bool) { return old + 1, true })
+	tc.Delete("d")
+	tc.DeleteExpired()
+
+	if keys := tc.KeysForTag("t"); !reflect.DeepEqual(keys, []string{"b"}) {
+		t.Error("unexpected keys for tag:", keys)
+	}
+	if len(tc.tags.tags) != 1 || len(tc.tags.keys) != 1 {
+		t.Error("tag index was not cleaned up:", tc.tags.tags, tc.tags.keys)
+	}
+	if n := tc.InvalidateTag("t"); n != 1 {
+		t.Error("unexpected number of invalidated items:", n)
+	}
+	if _, found := tc.Get("a"); !found {
+		t.Error("untagged item was invalidated")
+	}
+
+	tc.SetWithTags("e", 5, DefaultExpiration, "t")
+	tc.Flush()
+	if keys := tc.KeysForTag("t"); keys != nil {
+		t.Error("tags were not flushed:", keys)
+	}
+}
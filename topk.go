@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"time"
+)
+
+// KeyCount is the approximate number of times a key was read or written, as
+// reported by TopKeys.
+type KeyCount struct {
+	Key string
+	// Count is an upper bound of the number of times the key was observed
+	// since it started being tracked.
+	Count uint64
+	// Error is the maximum overestimation of Count: the key was observed at
+	// least Count-Error times.
+	Error uint64
+}
+
+// topKCheckDecayEvery is the number of observations between two checks of
+// whether the counts must be decayed, to avoid calling time.Now() on every
+// observation.
+const topKCheckDecayEvery = 64
+
+// topKTracker implements the Space-Saving algorithm: it tracks the counts of at
+// most capacity keys, an unknown key replacing the one with the lowest count
+// and inheriting its count as error.
+type topKTracker struct {
+	mu           sync.Mutex
+	capacity     int
+	decay        time.Duration
+	lastDecay    time.Time
+	observations int
+	entries      map[string]*topKEntry
+	heap         topKHeap
+}
+
+type topKEntry struct {
+	KeyCount
+	index int
+}
+
+// topKHeap is a min-heap of entries ordered by count.
+type topKHeap []*topKEntry
+
+func (h topKHeap) Len() int           { return len(h) }
+func (h topKHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+
+func (h topKHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *topKHeap) Push(x any) {
+	e := x.(*topKEntry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *topKHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
+
+func newTopKTracker(capacity int, decay time.Duration) *topKTracker {
+	return &topKTracker{
+		capacity:  capacity,
+		decay:     decay,
+		lastDecay: time.Now(),
+		entries:   make(map[string]*topKEntry, capacity),
+		heap:      make(topKHeap, 0, capacity),
+	}
+}
+
+func (t *topKTracker) observe(k string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.decay > 0 {
+		t.observations++
+		if t.observations >= topKCheckDecayEvery {
+			t.observations = 0
+			t.maybeDecay(time.Now())
+		}
+	}
+
+	if e, found := t.entries[k]; found {
+		e.Count++
+		heap.Fix(&t.heap, e.index)
+		return
+	}
+
+	if len(t.heap) < t.capacity {
+		e := &topKEntry{KeyCount: KeyCount{Key: k, Count: 1}}
+		t.entries[k] = e
+		heap.Push(&t.heap, e)
+		return
+	}
+
+	// Replace the key with the lowest count.
+	e := t.heap[0]
+	delete(t.entries, e.Key)
+	e.Key = k
+	e.Error = e.Count
+	e.Count++
+	t.entries[k] = e
+	heap.Fix(&t.heap, 0)
+}
+
+// maybeDecay halves all the counts once per elapsed decay interval and drops
+// the keys whose count reaches zero.
+func (t *topKTracker) maybeDecay(now time.Time) {
+	for now.Sub(t.lastDecay) >= t.decay {
+		t.lastDecay = t.lastDecay.Add(t.decay)
+
+		kept := t.heap[:0]
+		for _, e := range t.heap {
+			e.Count >>= 1
+			e.Error >>= 1
+			if e.Count == 0 {
+				delete(t.entries, e.Key)
+				continue
+			}
+			kept = append(kept, e)
+		}
+		for i := len(kept); i < len(t.heap); i++ {
+			t.heap[i] = nil
+		}
+		t.heap = kept
+		for i, e := range t.heap {
+			e.index = i
+		}
+		heap.Init(&t.heap)
+
+		if len(t.heap) == 0 {
+			t.lastDecay = now
+		}
+	}
+}
+
+func (t *topKTracker) top(n int) []KeyCount {
+	t.mu.Lock()
+	if t.decay > 0 {
+		t.maybeDecay(time.Now())
+	}
+	counts := make([]KeyCount, 0, len(t.heap))
+	for _, e := range t.heap {
+		counts = append(counts, e.KeyCount)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Key < counts[j].Key
+	})
+	if n >= 0 && n < len(counts) {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// TrackTopKeys enables the tracking of the most frequently read and written
+// keys, see TopKeys. At most capacity keys are tracked using the Space-Saving
+// algorithm, so counts are approximate when more distinct keys are observed.
+// If decay is greater than 0, all counts are halved every decay interval so
+// that keys which are not hot anymore eventually leave the top. Calling it again
+// resets the tracked counts. If capacity is lower than 1, tracking is disabled.
+//
+// Tracking serializes the observations made by Get and Set, it is therefore
+// disabled by default.
+func (c *anyCache[T]) TrackTopKeys(capacity int, decay time.Duration) {
+	if capacity < 1 {
+		c.topK.Store(nil)
+		return
+	}
+	c.topK.Store(newTopKTracker(capacity, decay))
+}
+
+// TopKeys returns the n most frequently read and written keys, in descending
+// order of their approximate counts, or all the tracked keys if n is negative.
+// It returns nil if tracking is disabled, see TrackTopKeys.
+func (c *anyCache[T]) TopKeys(n int) []KeyCount {
+	t := c.topK.Load()
+	if t == nil {
+		return nil
+	}
+	return t.top(n)
+}
+
+// observeKey records an access to k if top keys tracking is enabled.
+func (c *anyCache[T]) observeKey(k string) {
+	if t := c.topK.Load(); t != nil {
+		t.observe(k)
+	}
+}
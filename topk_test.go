@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTopKeys(t *testing.T) {
+	tc := New[int](DefaultExpiration, 0)
+	if keys := tc.TopKeys(10); keys != nil {
+		t.Error("TopKeys returned keys although tracking is disabled:", keys)
+	}
+
+	tc.TrackTopKeys(10, 0)
+	for i := 0; i < 100; i++ {
+		tc.Get("hot")
+		tc.Get(strconv.Itoa(i))
+		if i%2 == 0 {
+			tc.Set("warm", i, DefaultExpiration)
+		}
+	}
+
+	keys := tc.TopKeys(2)
+	if len(keys) != 2 {
+		t.Fatal("TopKeys did not return 2 keys:", keys)
+	}
+	if keys[0].Key != "hot" || keys[0].Count-keys[0].Error < 100 {
+		t.Error("hot is not the top key:", keys[0])
+	}
+	if keys[1].Key != "warm" || keys[1].Count-keys[1].Error < 50 {
+		t.Error("warm is not the second key:", keys[1])
+	}
+	if n := len(tc.TopKeys(-1)); n != 10 {
+		t.Error("more keys than the capacity are tracked:", n)
+	}
+
+	tc.TrackTopKeys(0, 0)
+	if keys := tc.TopKeys(10); keys != nil {
+		t.Error("TopKeys returned keys although tracking is disabled:", keys)
+	}
+}
+
+func TestTopKeysDecay(t *testing.T) {
+	tr := newTopKTracker(10, time.Minute)
+	for i := 0; i < 8; i++ {
+		tr.observe("foo")
+	}
+	tr.observe("bar")
+
+	tr.maybeDecay(tr.lastDecay.Add(time.Minute))
+	keys := tr.top(-1)
+	if len(keys) != 1 || keys[0].Key != "foo" || keys[0].Count != 4 {
+		t.Error("counts were not halved:", keys)
+	}
+
+	tr.maybeDecay(tr.lastDecay.Add(3 * time.Minute))
+	if keys := tr.top(-1); len(keys) != 0 {
+		t.Error("decayed keys were not dropped:", keys)
+	}
+}
+
+func BenchmarkCacheGetTopKeys(b *testing.B) {
+	b.StopTimer()
+	tc := New[string](DefaultExpiration, 0)
+	tc.TrackTopKeys(100, time.Minute)
+	tc.Set("foo", "bar", DefaultExpiration)
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		tc.Get("foo")
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"time"
+)
+
+// nextVersion returns a new item version. The cache must be locked.
+func (c *anyCache[T]) nextVersion() uint64 {
+	c.version++
+	return c.version
+}
+
+// GetWithVersion gets an item and its version from the cache. Returns the item
+// or nil, its version and a bool indicating whether the key was found. The
+// version can be passed to SetIfVersion to only set the item if it has not been
+// modified in the meantime.
+func (c *anyCache[T]) GetWithVersion(k string) (T, uint64, bool) {
+	c.observeKey(k)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	item, found := c.items[k]
+	if !found || item.Expired() {
+		c.countMiss(k)
+		var ret T
+		return ret, 0, false
+	}
+	c.stats.Load().addHits(1)
+	if c.meta != nil {
+		c.accessed(k, time.Now().UnixNano())
+	}
+	return item.Object, item.Version, true
+}
+
+// SetIfVersion sets an item only if its version is the given one, i.e. it has
+// not been set or modified since GetWithVersion returned it. The duration is
+// handled as in Set. If version is 0, the item is only set if the key does not
+// exist or has expired, like Add. Returns ErrNotFound if the key does not exist
+// or has expired and version is not 0, ErrVersionMismatch if the item has
+// another version.
+func (c *anyCache[T]) SetIfVersion(k string, x T, d time.Duration, version uint64) error {
+	c.mu.Lock()
+	defer c.unlock()
+
+	item, found := c.items[k]
+	if found && item.Expired() {
+		found = false
+	}
+	switch {
+	case !found && version != 0:
+		return keyError(k, ErrNotFound)
+	case found && item.Version != version:
+		return keyError(k, ErrVersionMismatch)
+	}
+	c.set(k, x, d)
+	return nil
+}
+
+// initVersions makes the versions given by the cache not collide with the
+// ones of the items it was created with, and gives a version to the items
+// without one, e.g. loaded from a snapshot taken before items had versions.
+func (c *anyCache[T]) initVersions() {
+	for _, item := range c.items {
+		if item.Version > c.version {
+			c.version = item.Version
+		}
+	}
+	for k, item := range c.items {
+		if item.Version == 0 {
+			item.Version = c.nextVersion()
+			c.items[k] = item
+		}
+	}
+}
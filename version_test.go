@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetWithVersion(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+	if _, v, found := tc.GetWithVersion("foo"); found || v != 0 {
+		t.Error("found a missing key:", v)
+	}
+
+	tc.Set("foo", 1, DefaultExpiration)
+	x, v1, found := tc.GetWithVersion("foo")
+	if !found || x != 1 || v1 == 0 {
+		t.Error("GetWithVersion did not return foo:", x, v1, found)
+	}
+
+	tc.Increment("foo", 1)
+	_, v2, _ := tc.GetWithVersion("foo")
+	if v2 == v1 {
+		t.Error("Increment did not change the version of foo")
+	}
+
+	tc.Set("foo", 2, DefaultExpiration)
+	_, v3, _ := tc.GetWithVersion("foo")
+	if v3 == v2 {
+		t.Error("Set did not change the version of foo")
+	}
+
+	tc.Set("expired", 1, time.Nanosecond)
+	<-time.After(time.Millisecond)
+	if _, _, found := tc.GetWithVersion("expired"); found {
+		t.Error("found an expired key")
+	}
+}
+
+func TestSetIfVersion(t *testing.T) {
+	tc := New[string](DefaultExpiration, 0)
+
+	if err := tc.SetIfVersion("foo", "bar", DefaultExpiration, 1); !errors.Is(err, ErrNotFound) {
+		t.Error("SetIfVersion did not return ErrNotFound:", err)
+	}
+	if err := tc.SetIfVersion("foo", "bar", DefaultExpiration, 0); err != nil {
+		t.Error("SetIfVersion did not add foo:", err)
+	}
+	if err := tc.SetIfVersion("foo", "baz", DefaultExpiration, 0); !errors.Is(err, ErrVersionMismatch) {
+		t.Error("SetIfVersion added an existing key:", err)
+	}
+
+	_, v, _ := tc.GetWithVersion("foo")
+	if err := tc.SetIfVersion("foo", "baz", DefaultExpiration, v); err != nil {
+		t.Error("SetIfVersion did not set foo:", err)
+	}
+	if err := tc.SetIfVersion("foo", "qux", DefaultExpiration, v); !errors.Is(err, ErrVersionMismatch) {
+		t.Error("SetIfVersion set foo with a stale version:", err)
+	}
+	if x, _ := tc.Get("foo"); x != "baz" {
+		t.Error("foo was overwritten:", x)
+	}
+
+	// Deleting and setting again an item gives it a new version.
+	tc.Delete("foo")
+	tc.Set("foo", "bar", DefaultExpiration)
+	if err := tc.SetIfVersion("foo", "qux", DefaultExpiration, v); !errors.Is(err, ErrVersionMismatch) {
+		t.Error("SetIfVersion set a recreated item with a stale version:", err)
+	}
+}
+
+func TestSetIfVersionConcurrent(t *testing.T) {
+	tc := NewNumeric[int](DefaultExpiration, 0)
+	tc.Set("counter", 0, DefaultExpiration)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				x, v, _ := tc.GetWithVersion("counter")
+				err := tc.SetIfVersion("counter", x+1, DefaultExpiration, v)
+				if err == nil {
+					return
+				}
+				if !errors.Is(err, ErrVersionMismatch) {
+					t.Error("unexpected error:", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if x, _ := tc.Get("counter"); x != 50 {
+		t.Error("concurrent gets/cas cycles lost updates:", x)
+	}
+}
+
+func TestNewFromVersion(t *testing.T) {
+	tc := NewFrom[string](DefaultExpiration, 0, map[string]Item[string]{
+		"foo": {Object: "bar", Version: 10},
+	})
+	tc.Set("baz", "qux", DefaultExpiration)
+	if _, v, _ := tc.GetWithVersion("baz"); v <= 10 {
+		t.Error("new version collides with existing ones:", v)
+	}
+}
+
+func TestSetIfVersionZeroVersionItems(t *testing.T) {
+	tc := NewFrom[string](DefaultExpiration, 0, map[string]Item[string]{
+		"foo": {Object: "bar"},
+	})
+	if err := tc.SetIfVersion("foo", "baz", DefaultExpiration, 0); !errors.Is(err, ErrVersionMismatch) {
+		t.Error("item without version was overwritten:", err)
+	}
+	x, v, _ := tc.GetWithVersion("foo")
+	if x != "bar" || v == 0 {
+		t.Error("item was not given a version:", x, v)
+	}
+	if err := tc.SetIfVersion("foo", "baz", DefaultExpiration, v); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// WindowedCache counts values per key over time windows, e.g. the requests of
+// the last 1, 5 and 15 minutes. Each key holds a ring of buckets, each bucket
+// summing the values added during a fixed interval of time. Windows are rounded
+// up to a number of buckets, the current bucket included, and cannot be longer
+// than the ring.
+//
+// Rings are stored in an AnyCache. A key expires once no value has been added
+// to it for the duration of the ring, and is deleted by the janitor.
+type WindowedCache[T Numeric] struct {
+	c       *AnyCache[*windowRing[T]]
+	bucket  int64
+	buckets int
+	now     func() time.Time
+}
+
+// windowRing holds the sums of the last buckets of a key. stamps holds the
+// index of the interval of time each bucket sums, buckets which are not part of
+// the ring anymore are reset when reused.
+type windowRing[T Numeric] struct {
+	sums   []T
+	stamps []int64
+}
+
+func (r *windowRing[T]) add(i int64, n T) {
+	slot := int(i % int64(len(r.sums)))
+	if r.stamps[slot] != i {
+		r.sums[slot] = 0
+		r.stamps[slot] = i
+	}
+	r.sums[slot] += n
+}
+
+// values returns the sums of the nb buckets ending with the bucket i, zero for
+// the buckets without values.
+func (r *windowRing[T]) values(i int64, nb int) []T {
+	values := make([]T, nb)
+	for j := 0; j < nb; j++ {
+		slot := int((i - int64(j)) % int64(len(r.sums)))
+		if r.stamps[slot] == i-int64(j) {
+			values[j] = r.sums[slot]
+		}
+	}
+	return values
+}
+
+// span returns the duration covered by the ring.
+func (c *WindowedCache[T]) span() time.Duration {
+	return time.Duration(c.bucket * int64(c.buckets))
+}
+
+// bucketsIn returns the number of buckets of the given window.
+func (c *WindowedCache[T]) bucketsIn(window time.Duration) int {
+	nb := int((int64(window) + c.bucket - 1) / c.bucket)
+	if nb < 1 {
+		return 1
+	}
+	if nb > c.buckets {
+		return c.buckets
+	}
+	return nb
+}
+
+// Add adds n to the current bucket of k, creating the key if it does not exist
+// or has expired, and postpones its expiration.
+func (c *WindowedCache[T]) Add(k string, n T) {
+	i := c.now().UnixNano() / c.bucket
+
+	c.c.mu.Lock()
+	defer c.c.unlock()
+
+	r, found := c.c.get(k)
+	if !found {
+		r = &windowRing[T]{
+			sums:   make([]T, c.buckets),
+			stamps: make([]int64, c.buckets),
+		}
+		for j := range r.stamps {
+			r.stamps[j] = -1
+		}
+	}
+	r.add(i, n)
+	c.c.set(k, r, c.span())
+}
+
+// Values returns the sums of the buckets of k in the given window, the current
+// one first, and false if the key was not found.
+func (c *WindowedCache[T]) Values(k string, window time.Duration) ([]T, bool) {
+	i := c.now().UnixNano() / c.bucket
+
+	c.c.mu.RLock()
+	defer c.c.mu.RUnlock()
+
+	r, found := c.c.get(k)
+	if !found {
+		return nil, false
+	}
+	return r.values(i, c.bucketsIn(window)), true
+}
+
+// Sum returns the sum of the values added to k in the given window, and false
+// if the key was not found.
+func (c *WindowedCache[T]) Sum(k string, window time.Duration) (T, bool) {
+	values, found := c.Values(k, window)
+	var sum T
+	for _, v := range values {
+		sum += v
+	}
+	return sum, found
+}
+
+// Rate returns the sum of the values added to k in the given window per
+// second, and false if the key was not found. The window is rounded up to a
+// number of buckets.
+func (c *WindowedCache[T]) Rate(k string, window time.Duration) (float64, bool) {
+	values, found := c.Values(k, window)
+	if !found {
+		return 0, false
+	}
+	var sum float64
+	for _, v := range values {
+		sum += float64(v)
+	}
+	return sum / (time.Duration(c.bucket) * time.Duration(len(values))).Seconds(), true
+}
+
+// Percentile returns the p-th percentile, with p between 0 and 100, of the
+// sums of the buckets of k in the given window using the nearest-rank method,
+// and false if the key was not found. Buckets without values count as zero.
+func (c *WindowedCache[T]) Percentile(k string, window time.Duration, p float64) (T, bool) {
+	values, found := c.Values(k, window)
+	if !found {
+		return 0, false
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	rank := int(math.Ceil(p / 100 * float64(len(values))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(values) {
+		rank = len(values)
+	}
+	return values[rank-1], true
+}
+
+// Delete deletes the buckets of k. Does nothing if the key is not in the cache.
+func (c *WindowedCache[T]) Delete(k string) {
+	c.c.Delete(k)
+}
+
+// ItemCount returns the number of keys in the cache. This may include keys that
+// have expired, but have not yet been cleaned up.
+func (c *WindowedCache[T]) ItemCount() int {
+	return c.c.ItemCount()
+}
+
+// Flush deletes all keys from the cache.
+func (c *WindowedCache[T]) Flush() {
+	c.c.Flush()
+}
+
+// NewWindowed[T Numeric](...) returns a new *WindowedCache[T] whose keys hold
+// the given number of buckets of the given duration each, the longest window
+// being bucket*buckets. Expired keys are deleted every cleanupInterval, see
+// NewAny.
+func NewWindowed[T Numeric](bucket time.Duration, buckets int, cleanupInterval time.Duration) *WindowedCache[T] {
+	if bucket < 1 {
+		bucket = 1
+	}
+	if buckets < 1 {
+		buckets = 1
+	}
+	return &WindowedCache[T]{
+		c:       NewAny[*windowRing[T]](NoExpiration, cleanupInterval),
+		bucket:  int64(bucket),
+		buckets: buckets,
+		now:     time.Now,
+	}
+}
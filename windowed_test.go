@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestWindowed(bucket time.Duration, buckets int) (*WindowedCache[int], *time.Time) {
+	now := time.Unix(1_700_000_040, 0)
+	tc := NewWindowed[int](bucket, buckets, 0)
+	tc.now = func() time.Time { return now }
+	return tc, &now
+}
+
+func TestWindowedCache(t *testing.T) {
+	tc, now := newTestWindowed(time.Minute, 15)
+
+	if _, found := tc.Sum("foo", time.Minute); found {
+		t.Error("found a missing key")
+	}
+
+	// 1 request per minute for 10 minutes, then 10 in the last minute.
+	for i := 0; i < 10; i++ {
+		tc.Add("foo", 1)
+		*now = now.Add(time.Minute)
+	}
+	for i := 0; i < 10; i++ {
+		tc.Add("foo", 1)
+	}
+
+	tests := []struct {
+		window time.Duration
+		sum    int
+	}{
+		{time.Minute, 10},
+		{5 * time.Minute, 14},
+		{15 * time.Minute, 20},
+		{time.Hour, 20},
+		{0, 10},
+	}
+	for _, tt := range tests {
+		if sum, _ := tc.Sum("foo", tt.window); sum != tt.sum {
+			t.Errorf("sum over %s is %d, want %d", tt.window, sum, tt.sum)
+		}
+	}
+
+	if rate, _ := tc.Rate("foo", 5*time.Minute); rate != 14.0/300 {
+		t.Error("unexpected rate:", rate)
+	}
+	if p, _ := tc.Percentile("foo", 5*time.Minute, 50); p != 1 {
+		t.Error("unexpected median:", p)
+	}
+	if p, _ := tc.Percentile("foo", 5*time.Minute, 100); p != 10 {
+		t.Error("unexpected max:", p)
+	}
+	if p, _ := tc.Percentile("foo", 15*time.Minute, 10); p != 0 {
+		t.Error("empty buckets were not counted:", p)
+	}
+
+	// Buckets which left the ring are not counted when it wraps around.
+	*now = now.Add(14 * time.Minute)
+	tc.Add("foo", 3)
+	if sum, _ := tc.Sum("foo", time.Hour); sum != 13 {
+		t.Error("unexpected sum after wrapping around:", sum)
+	}
+	if values, _ := tc.Values("foo", 3*time.Minute); len(values) != 3 || values[0] != 3 || values[1] != 0 {
+		t.Error("unexpected values:", values)
+	}
+
+	tc.Delete("foo")
+	if _, found := tc.Sum("foo", time.Minute); found {
+		t.Error("foo was not deleted")
+	}
+}
+
+func TestWindowedCacheExpiration(t *testing.T) {
+	tc := NewWindowed[float64](time.Millisecond, 5, 0)
+	tc.Add("foo", 1.5)
+	if sum, found := tc.Sum("foo", time.Second); !found || sum != 1.5 {
+		t.Error("unexpected sum:", sum, found)
+	}
+	<-time.After(10 * time.Millisecond)
+	if _, found := tc.Sum("foo", time.Second); found {
+		t.Error("inactive key did not expire")
+	}
+	tc.c.DeleteExpired()
+	if n := tc.ItemCount(); n != 0 {
+		t.Error("inactive key was not deleted:", n)
+	}
+}